@@ -16,8 +16,14 @@ limitations under the License.
 package cmd
 
 import (
+	"fmt"
+	"path/filepath"
+	"sort"
+
 	"github.com/fatih/color"
+	"github.com/mitchellh/mapstructure"
 	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
 	"github.com/wisdommatt/sdkgen/graphql"
 	"github.com/wisdommatt/sdkgen/pkg/log"
 )
@@ -27,22 +33,152 @@ var graphqlCmd = &cobra.Command{
 	Use:   "graphql",
 	Short: "Generate SDK client from graphql schema",
 	Run: func(cmd *cobra.Command, args []string) {
-		schemaFile, _ := cmd.Flags().GetString("schema")
-		if schemaFile == "" {
-			log.Fatalln(color.FgRed, "ERROR", "--schema is required")
+		schemaPaths, _ := cmd.Flags().GetStringArray("schema")
+		endpoint, _ := cmd.Flags().GetString("endpoint")
+		if len(schemaPaths) == 0 && endpoint == "" {
+			log.Fatalln(color.FgRed, "ERROR", "--schema or --endpoint is required")
 		}
 		output, _ := cmd.Flags().GetString("output")
 		if output == "" {
 			log.Fatalln(color.FgRed, "ERROR", "--output is required")
 		}
-		err := graphql.GenerateGoSDK(schemaFile, output)
+		scalars, err := scalarMappings(cmd)
+		if err != nil {
+			log.Fatalln(color.FgRed, "ERROR", err.Error())
+		}
+		var schema *graphql.Schema
+		if endpoint != "" {
+			headers := map[string]string{}
+			headerFlags, _ := cmd.Flags().GetStringArray("header")
+			for _, header := range headerFlags {
+				key, value, err := graphql.ParseHeader(header)
+				if err != nil {
+					log.Fatalln(color.FgRed, "ERROR", err.Error())
+				}
+				headers[key] = value
+			}
+			schema, err = graphql.LoadGraphqlSchemaFromEndpoint(cmd.Context(), endpoint, headers)
+		} else {
+			var schemaFiles []string
+			schemaFiles, err = graphql.ExpandSchemaFiles(schemaPaths...)
+			if err == nil {
+				schema, err = graphql.LoadGraphqlSchema(schemaFiles...)
+			}
+		}
+		if err != nil {
+			log.Fatalln(color.FgRed, "ERROR", err.Error())
+		}
+		err = schema.MapScalars(scalars...)
+		if err != nil {
+			log.Fatalln(color.FgRed, "ERROR", err.Error())
+		}
+		directives, err := directiveMappings()
+		if err != nil {
+			log.Fatalln(color.FgRed, "ERROR", err.Error())
+		}
+		err = schema.MapDirectives(directives...)
 		if err != nil {
 			log.Fatalln(color.FgRed, "ERROR", err.Error())
 		}
+		if excludeDeprecated, _ := cmd.Flags().GetBool("exclude-deprecated"); excludeDeprecated {
+			schema.ExcludeDeprecated()
+		}
+		schema.StrictEnums, _ = cmd.Flags().GetBool("strict-enums")
+		err = graphql.GenerateClient(schema, output)
+		if err != nil {
+			log.Fatalln(color.FgRed, "ERROR", err.Error())
+		}
+		operationPatterns, _ := cmd.Flags().GetStringSlice("operations")
+		manifest, _ := cmd.Flags().GetString("manifest")
+		if manifest != "" && len(operationPatterns) == 0 {
+			log.Fatalln(color.FgRed, "ERROR", "--manifest requires --operations")
+		}
+		if len(operationPatterns) > 0 {
+			operationFiles := []string{}
+			for _, pattern := range operationPatterns {
+				files, err := filepath.Glob(pattern)
+				if err != nil {
+					log.Fatalln(color.FgRed, "ERROR", err.Error())
+				}
+				if len(files) == 0 {
+					log.Fatalln(color.FgRed, "ERROR", "no operation files match "+pattern)
+				}
+				operationFiles = append(operationFiles, files...)
+			}
+			err = graphql.GenerateOperationsClient(schema, operationFiles, output)
+			if err != nil {
+				log.Fatalln(color.FgRed, "ERROR", err.Error())
+			}
+			if manifest != "" {
+				err = graphql.GeneratePersistedQueryManifest(schema, operationFiles, manifest)
+				if err != nil {
+					log.Fatalln(color.FgRed, "ERROR", err.Error())
+				}
+			}
+		}
 		log.Fatalln(color.FgGreen, "COMPLETED", "API SDK client generated successfully")
 	},
 }
 
+// scalarMappings returns the scalar mappings of the graphql.scalars config
+// section followed by the --scalar flags. Config values are either a type
+// or an object with type, import, marshal and unmarshal keys.
+func scalarMappings(cmd *cobra.Command) ([]graphql.ScalarMapping, error) {
+	mappings := []graphql.ScalarMapping{}
+	config := viper.GetStringMap("graphql.scalars")
+	names := []string{}
+	for name := range config {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		// viper lower cases the keys, the scalar names are matched case
+		// insensitively against the schema.
+		mapping := graphql.ScalarMapping{Scalar: name}
+		switch value := config[name].(type) {
+		case string:
+			mapping.Type = value
+		default:
+			if err := mapstructure.Decode(value, &mapping); err != nil {
+				return nil, fmt.Errorf("graphql.scalars.%s: %w", name, err)
+			}
+		}
+		mappings = append(mappings, mapping)
+	}
+	flags, _ := cmd.Flags().GetStringArray("scalar")
+	for _, flag := range flags {
+		mapping, err := graphql.ParseScalarMapping(flag)
+		if err != nil {
+			return nil, err
+		}
+		mappings = append(mappings, mapping)
+	}
+	return mappings, nil
+}
+
+// directiveMappings returns the directive mappings of the
+// graphql.directives config section, values are objects with name and
+// tags keys.
+func directiveMappings() ([]graphql.DirectiveMapping, error) {
+	mappings := []graphql.DirectiveMapping{}
+	config := viper.GetStringMap("graphql.directives")
+	names := []string{}
+	for name := range config {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		// viper lower cases the keys, the directive names are matched case
+		// insensitively against the schema.
+		mapping := graphql.DirectiveMapping{Directive: name}
+		if err := mapstructure.Decode(config[name], &mapping); err != nil {
+			return nil, fmt.Errorf("graphql.directives.%s: %w", name, err)
+		}
+		mappings = append(mappings, mapping)
+	}
+	return mappings, nil
+}
+
 func init() {
 	rootCmd.AddCommand(graphqlCmd)
 
@@ -50,8 +186,15 @@ func init() {
 
 	// Cobra supports Persistent Flags which will work for this command
 	// and all subcommands, e.g.:
-	graphqlCmd.Flags().String("schema", "", "path to graphql schema file")
+	graphqlCmd.Flags().StringArray("schema", nil, "path, glob or directory of graphql schema or introspection result files, can be repeated")
+	graphqlCmd.Flags().String("endpoint", "", "url of a graphql endpoint to load the schema from with introspection")
+	graphqlCmd.Flags().StringArray("header", nil, "header sent with the introspection query e.g. 'Authorization: Bearer token'")
 	graphqlCmd.Flags().String("output", "", "name/path of generated client package")
+	graphqlCmd.Flags().StringSlice("operations", nil, "glob of graphql operation files to generate typed functions for")
+	graphqlCmd.Flags().String("manifest", "", "path of a persisted query manifest written for the --operations documents")
+	graphqlCmd.Flags().Bool("exclude-deprecated", false, "leave deprecated fields, arguments and enum values out of the generated client")
+	graphqlCmd.Flags().Bool("strict-enums", false, "make the generated enums reject values that are not defined in the schema")
+	graphqlCmd.Flags().StringArray("scalar", nil, "maps a graphql scalar to a Go type e.g. UUID=github.com/google/uuid.UUID")
 
 	// Cobra supports local flags which will only run when this command
 	// is called directly, e.g.:
@@ -16,8 +16,12 @@ limitations under the License.
 package cmd
 
 import (
+	"fmt"
+
 	"github.com/fatih/color"
+	"github.com/mitchellh/mapstructure"
 	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
 	"github.com/wisdommatt/sdkgen/openapi"
 	"github.com/wisdommatt/sdkgen/pkg/log"
 )
@@ -35,7 +39,15 @@ var openapiCmd = &cobra.Command{
 		if output == "" {
 			log.Fatalln(color.FgRed, "ERROR", "--output is required")
 		}
-		err := openapi.GenerateGoSDK(schemaFile, output)
+		schema, err := openapi.LoadOpenApiSchema(schemaFile)
+		if err != nil {
+			log.Fatalln(color.FgRed, "ERROR", err.Error())
+		}
+		err = paginationHeuristics(&schema.Pagination)
+		if err != nil {
+			log.Fatalln(color.FgRed, "ERROR", err.Error())
+		}
+		err = openapi.GenerateClient(schema, output)
 		if err != nil {
 			log.Fatalln(color.FgRed, "ERROR", err.Error())
 		}
@@ -43,6 +55,20 @@ var openapiCmd = &cobra.Command{
 	},
 }
 
+// paginationHeuristics overrides the pagination heuristics with the
+// openapi.pagination config section, the keys are the heuristics field
+// names (e.g limitParams) matched case insensitively.
+func paginationHeuristics(heuristics *openapi.PaginationHeuristics) error {
+	config := viper.GetStringMap("openapi.pagination")
+	if len(config) == 0 {
+		return nil
+	}
+	if err := mapstructure.Decode(config, heuristics); err != nil {
+		return fmt.Errorf("openapi.pagination: %w", err)
+	}
+	return nil
+}
+
 func init() {
 	rootCmd.AddCommand(openapiCmd)
 
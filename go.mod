@@ -5,10 +5,8 @@ go 1.16
 require (
 	github.com/fatih/color v1.13.0
 	github.com/iancoleman/strcase v0.2.0
-	github.com/machinebox/graphql v0.2.2
-	github.com/matryer/is v1.4.0 // indirect
 	github.com/mitchellh/go-homedir v1.1.0
-	github.com/pkg/errors v0.9.1 // indirect
+	github.com/mitchellh/mapstructure v1.4.3
 	github.com/spf13/cobra v1.3.0
 	github.com/spf13/viper v1.10.1
 	github.com/vektah/gqlparser v1.3.1
@@ -0,0 +1,135 @@
+package graphql
+
+import (
+	"bytes"
+	"strings"
+
+	"github.com/vektah/gqlparser/ast"
+	"github.com/vektah/gqlparser/formatter"
+)
+
+// batchPlaceholder prefixes the root field aliases and the variables of
+// batch selections, it is replaced with the prefix of the operation in
+// aliased batches.
+const batchPlaceholder = "__batch__"
+
+// batchSelection returns the root selection set of an operation with
+// prefixed root field aliases and variables, together with the fragments
+// it uses. An empty selection is returned for operations that can not be
+// merged into an aliased batch document because fields of their root
+// selection set or the variables they use are defined in fragments.
+func batchSelection(document *ast.QueryDocument, definition *ast.OperationDefinition) (string, []string) {
+	fragments := usedFragments(document, definition.SelectionSet, map[string]bool{})
+	for _, fragment := range fragments {
+		if selectionUsesVariables(fragment.SelectionSet) {
+			return "", nil
+		}
+	}
+	undo := []func(){}
+	defer func() {
+		for _, restore := range undo {
+			restore()
+		}
+	}()
+	if !prefixRootFields(definition.SelectionSet, &undo) {
+		return "", nil
+	}
+	prefixVariables(definition.SelectionSet, &undo)
+
+	buffer := &bytes.Buffer{}
+	formatter.NewFormatter(buffer).FormatQueryDocument(&ast.QueryDocument{
+		Operations: ast.OperationList{{
+			Operation:    definition.Operation,
+			SelectionSet: definition.SelectionSet,
+		}},
+	})
+	selection := strings.TrimSpace(buffer.String())
+	selection = strings.TrimSpace(selection[strings.Index(selection, "{")+1 : len(selection)-1])
+
+	fragmentDocuments := []string{}
+	for _, fragment := range fragments {
+		buffer.Reset()
+		formatter.NewFormatter(buffer).FormatQueryDocument(&ast.QueryDocument{
+			Fragments: ast.FragmentDefinitionList{fragment},
+		})
+		fragmentDocuments = append(fragmentDocuments, strings.TrimSpace(buffer.String()))
+	}
+	return selection, fragmentDocuments
+}
+
+// prefixRootFields prefixes the aliases of the root fields, false is
+// returned if root fields are spread from named fragments.
+func prefixRootFields(selectionSet ast.SelectionSet, undo *[]func()) bool {
+	for _, selection := range selectionSet {
+		switch selection := selection.(type) {
+		case *ast.Field:
+			field, alias := selection, selection.Alias
+			field.Alias = batchPlaceholder + alias
+			*undo = append(*undo, func() { field.Alias = alias })
+
+		case *ast.InlineFragment:
+			if !prefixRootFields(selection.SelectionSet, undo) {
+				return false
+			}
+
+		case *ast.FragmentSpread:
+			return false
+		}
+	}
+	return true
+}
+
+// prefixVariables prefixes the variables used in the arguments of the
+// fields and directives of a selection set.
+func prefixVariables(selectionSet ast.SelectionSet, undo *[]func()) {
+	for _, selection := range selectionSet {
+		switch selection := selection.(type) {
+		case *ast.Field:
+			for _, argument := range selection.Arguments {
+				prefixValue(argument.Value, undo)
+			}
+			prefixDirectiveVariables(selection.Directives, undo)
+			prefixVariables(selection.SelectionSet, undo)
+
+		case *ast.InlineFragment:
+			prefixDirectiveVariables(selection.Directives, undo)
+			prefixVariables(selection.SelectionSet, undo)
+
+		case *ast.FragmentSpread:
+			prefixDirectiveVariables(selection.Directives, undo)
+		}
+	}
+}
+
+func prefixDirectiveVariables(directives ast.DirectiveList, undo *[]func()) {
+	for _, directive := range directives {
+		for _, argument := range directive.Arguments {
+			prefixValue(argument.Value, undo)
+		}
+	}
+}
+
+func prefixValue(value *ast.Value, undo *[]func()) {
+	if value == nil {
+		return
+	}
+	if value.Kind == ast.Variable {
+		raw := value.Raw
+		value.Raw = batchPlaceholder + raw
+		*undo = append(*undo, func() { value.Raw = raw })
+	}
+	for _, child := range value.Children {
+		prefixValue(child.Value, undo)
+	}
+}
+
+// selectionUsesVariables returns true if variables are used in the
+// arguments of the fields and directives of a selection set.
+func selectionUsesVariables(selectionSet ast.SelectionSet) bool {
+	undo := []func(){}
+	prefixVariables(selectionSet, &undo)
+	for _, restore := range undo {
+		restore()
+	}
+	return len(undo) > 0
+}
@@ -0,0 +1,75 @@
+package graphql
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/vektah/gqlparser"
+	"github.com/vektah/gqlparser/ast"
+	"github.com/vektah/gqlparser/formatter"
+)
+
+func TestBatchSelection(t *testing.T) {
+	schema, err := LoadGraphqlSchema("testdata/batch.graphql")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := map[string]struct {
+		document  string
+		selection string
+		fragments []string
+	}{
+		"aliases and variables": {
+			document:  `query Q($id: ID!, $other: ID!) { post(id: $id) { id } other: user(id: $other) { name } }`,
+			selection: "__batch__post: post(id: $__batch__id) {\n\t\tid\n\t}\n\t__batch__other: user(id: $__batch__other) {\n\t\tname\n\t}",
+		},
+		"inline fragments and directives": {
+			document:  `query Q($id: ID!, $skip: Boolean!) { ... on Query @skip(if: $skip) { post(id: $id) { id } } }`,
+			selection: "... on Query @skip(if: $__batch__skip) {\n\t\t__batch__post: post(id: $__batch__id) {\n\t\t\tid\n\t\t}\n\t}",
+		},
+		"fragments": {
+			document:  `query Q { post(id: "1") { ...F } } fragment F on Post { title }`,
+			selection: "__batch__post: post(id: \"1\") {\n\t\t... F\n\t}",
+			fragments: []string{"fragment F on Post {\n\ttitle\n}"},
+		},
+		"root fragment spread": {
+			document: `query Q { ...R } fragment R on Query { post(id: "1") { id } }`,
+		},
+		"fragment with variables": {
+			document: `query Q($withName: Boolean!) { user(id: "1") { ...F } } fragment F on User { id name @include(if: $withName) }`,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			document, errs := gqlparser.LoadQuery(schema.AstSchema, test.document)
+			if errs != nil {
+				t.Fatal(errs)
+			}
+			before := formatDocument(document)
+			selection, fragments := batchSelection(document, document.Operations[0])
+			if selection != test.selection {
+				t.Errorf("got selection %q, want %q", selection, test.selection)
+			}
+			if len(fragments) != len(test.fragments) || (len(fragments) > 0 && fragments[0] != test.fragments[0]) {
+				t.Errorf("got fragments %q, want %q", fragments, test.fragments)
+			}
+			if after := formatDocument(document); after != before {
+				t.Errorf("the document was changed:\n%s", after)
+			}
+		})
+	}
+}
+
+func formatDocument(document *ast.QueryDocument) string {
+	buffer := &bytes.Buffer{}
+	formatter.NewFormatter(buffer).FormatQueryDocument(document)
+	return buffer.String()
+}
+
+func TestGeneratedBatch(t *testing.T) {
+	dir := generateOperationsTestClient(t, "testdata/batch.graphql", []string{"testdata/batch_operations.graphql"}, map[string]string{
+		"client_test.go": "testdata/batch_client_test.go",
+	})
+	runGo(t, dir, "vet", ".")
+	runGo(t, dir, "test", "-count=1", ".")
+}
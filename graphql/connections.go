@@ -0,0 +1,106 @@
+package graphql
+
+import (
+	"strings"
+
+	"github.com/vektah/gqlparser/ast"
+)
+
+// Connection contains the Go fields used by the node iterator of a query
+// field returning a relay cursor connection.
+type Connection struct {
+	EdgesField string
+	// EdgePointer is true if the edges are pointers which can be nil.
+	EdgePointer bool
+	NodeField   string
+	NodeType    string
+	NodePointer bool
+	// SelectionType is the selection type of the nodes, it is empty for
+	// leaf node types.
+	SelectionType    string
+	PageInfoField    string
+	PageInfoPointer  bool
+	HasNextPageField string
+	EndCursorField   string
+	// AfterPointer is true if the after argument is a *string, it is an
+	// interface{} otherwise.
+	AfterPointer bool
+}
+
+// connection returns the connection of a query field which returns a
+// relay cursor connection (edges { node } and pageInfo { hasNextPage
+// endCursor }) and has the first and after arguments, nil is returned for
+// other fields.
+func connection(schema *Schema, field *ast.FieldDefinition) (*Connection, error) {
+	if field.Type.Elem != nil || field.Arguments.ForName("first") == nil {
+		return nil, nil
+	}
+	after := field.Arguments.ForName("after")
+	if after == nil {
+		return nil, nil
+	}
+	afterType := extractFieldTypeName(schema, after.Name, after.Type)
+	if afterType != "*string" && afterType != "interface{}" {
+		return nil, nil
+	}
+	definition, ok := schema.Objects[field.Type.Name()]
+	if !ok {
+		return nil, nil
+	}
+	edges := definition.Fields.ForName("edges")
+	pageInfo := definition.Fields.ForName("pageInfo")
+	if edges == nil || edges.Type.Elem == nil || pageInfo == nil {
+		return nil, nil
+	}
+	edge, ok := schema.Objects[edges.Type.Name()]
+	if !ok || edge.Fields.ForName("node") == nil {
+		return nil, nil
+	}
+	info, ok := schema.Objects[pageInfo.Type.Name()]
+	if !ok || info.Fields.ForName("hasNextPage") == nil || info.Fields.ForName("endCursor") == nil {
+		return nil, nil
+	}
+	node := edge.Fields.ForName("node")
+
+	// the Go types of the fields as declared in the generated structs.
+	goType := func(object *ast.Definition, field *ast.FieldDefinition) string {
+		return extractFieldTypeName(schema, field.Name, implementedFieldType(schema, object, field), 1)
+	}
+	conn := &Connection{
+		EdgePointer:     strings.HasPrefix(goType(definition, edges), "[]*"),
+		NodeType:        goType(edge, node),
+		SelectionType:   selectionTypeName(schema, node.Type),
+		PageInfoPointer: strings.HasPrefix(goType(definition, pageInfo), "*"),
+		AfterPointer:    afterType == "*string",
+	}
+	conn.NodePointer = strings.HasPrefix(conn.NodeType, "*")
+	fields := []struct {
+		name  *string
+		field *ast.FieldDefinition
+	}{
+		{&conn.EdgesField, edges},
+		{&conn.NodeField, node},
+		{&conn.PageInfoField, pageInfo},
+		{&conn.HasNextPageField, info.Fields.ForName("hasNextPage")},
+		{&conn.EndCursorField, info.Fields.ForName("endCursor")},
+	}
+	for _, f := range fields {
+		name, err := goFieldName(schema, f.field)
+		if err != nil {
+			return nil, err
+		}
+		*f.name = name
+	}
+	return conn, nil
+}
+
+// HasConnections returns true if a query field of the schema returns a
+// relay cursor connection.
+func (s *Schema) HasConnections() bool {
+	for _, field := range s.Queries {
+		if conn, _ := connection(s, field); conn != nil {
+			return true
+		}
+	}
+	return false
+}
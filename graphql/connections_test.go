@@ -0,0 +1,122 @@
+package graphql
+
+import (
+	"testing"
+
+	"github.com/vektah/gqlparser"
+	"github.com/vektah/gqlparser/ast"
+)
+
+func TestConnection(t *testing.T) {
+	astSchema, err := gqlparser.LoadSchema(&ast.Source{Input: `
+directive @goField(name: String) on FIELD_DEFINITION
+
+scalar Cursor
+
+type Query {
+  users(first: Int, after: String): UserConnection!
+  posts(first: Int!, after: Cursor): PostConnection
+  renamed(first: Int, after: String): RenamedConnection!
+  list(first: Int, after: String): [UserConnection!]!
+  withoutAfter(first: Int): UserConnection!
+  withoutFirst(after: String): UserConnection!
+  intCursor(first: Int, after: Int): UserConnection!
+  withoutPageInfo(first: Int, after: String): Edges!
+  withoutEndCursor(first: Int, after: String): PartialConnection!
+}
+
+type User {
+  id: ID!
+}
+
+type PageInfo {
+  hasNextPage: Boolean!
+  endCursor: String
+}
+
+type UserConnection {
+  edges: [UserEdge!]!
+  pageInfo: PageInfo!
+}
+
+type UserEdge {
+  node: User!
+}
+
+type PostConnection {
+  edges: [PostEdge]
+  pageInfo: PageInfo
+}
+
+type PostEdge {
+  node: String
+}
+
+type RenamedConnection {
+  edges: [UserEdge!]! @goField(name: "Items")
+  pageInfo: PageInfo! @goField(name: "Info")
+}
+
+type Edges {
+  edges: [UserEdge!]!
+}
+
+type PartialPageInfo {
+  hasNextPage: Boolean!
+}
+
+type PartialConnection {
+  edges: [UserEdge!]!
+  pageInfo: PartialPageInfo!
+}
+`})
+	if err != nil {
+		t.Fatal(err)
+	}
+	schema := parseSchema(NewSchema(astSchema))
+	tests := []struct {
+		field string
+		want  *Connection
+	}{
+		{"users", &Connection{
+			EdgesField: "Edges", NodeField: "Node", NodeType: "User", SelectionType: "UserSelection",
+			PageInfoField: "PageInfo", HasNextPageField: "HasNextPage", EndCursorField: "EndCursor", AfterPointer: true,
+		}},
+		{"posts", &Connection{
+			EdgesField: "Edges", EdgePointer: true, NodeField: "Node", NodeType: "*string", NodePointer: true,
+			PageInfoField: "PageInfo", PageInfoPointer: true, HasNextPageField: "HasNextPage", EndCursorField: "EndCursor",
+		}},
+		{"renamed", &Connection{
+			EdgesField: "Items", NodeField: "Node", NodeType: "User", SelectionType: "UserSelection",
+			PageInfoField: "Info", HasNextPageField: "HasNextPage", EndCursorField: "EndCursor", AfterPointer: true,
+		}},
+		{"list", nil},
+		{"withoutAfter", nil},
+		{"withoutFirst", nil},
+		{"intCursor", nil},
+		{"withoutPageInfo", nil},
+		{"withoutEndCursor", nil},
+	}
+	for _, test := range tests {
+		got, err := connection(schema, astSchema.Query.Fields.ForName(test.field))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if test.want == nil {
+			if got != nil {
+				t.Errorf("%s: got connection %+v, want none", test.field, got)
+			}
+			continue
+		}
+		if got == nil || *got != *test.want {
+			t.Errorf("%s: got connection %+v, want %+v", test.field, got, test.want)
+		}
+	}
+	if !schema.HasConnections() {
+		t.Error("the connections were not detected")
+	}
+}
+
+func TestGeneratedConnections(t *testing.T) {
+	testClient(t, "testdata/connections.graphql", "testdata/connections_client_test.go")
+}
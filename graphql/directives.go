@@ -0,0 +1,290 @@
+package graphql
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/iancoleman/strcase"
+	"github.com/vektah/gqlparser/ast"
+)
+
+// defaultDeprecationReason is the default reason of the @deprecated
+// directive.
+const defaultDeprecationReason = "No longer supported"
+
+// FieldOverride contains the Go name and the struct tags of the struct
+// field generated for a graphql field, the json tag is set by default.
+type FieldOverride struct {
+	Name string
+	Tags []StructTag
+}
+
+// StructTag is a key:"value" pair of a struct field tag.
+type StructTag struct {
+	Key   string
+	Value string
+}
+
+// DirectiveHook maps a directive of a graphql field to overrides of the
+// generated struct field.
+type DirectiveHook func(directive *ast.Directive, override *FieldOverride) error
+
+// defaultDirectiveHooks are the directive hooks of new schemas, they
+// support the gqlgen @goField(name:) and @goTag(key:, value:) directives.
+func defaultDirectiveHooks() map[string]DirectiveHook {
+	return map[string]DirectiveHook{
+		"goField": goFieldHook,
+		"goTag":   goTagHook,
+	}
+}
+
+func goFieldHook(directive *ast.Directive, override *FieldOverride) error {
+	if name := directiveArgument(directive, "name"); name != "" {
+		override.Name = name
+	}
+	return nil
+}
+
+func goTagHook(directive *ast.Directive, override *FieldOverride) error {
+	key := directiveArgument(directive, "key")
+	if key == "" {
+		return fmt.Errorf("@%s requires a key", directive.Name)
+	}
+	override.SetTag(key, directiveArgument(directive, "value"))
+	return nil
+}
+
+// DirectiveMapping maps a directive to the name and the struct tags of
+// the struct fields of the graphql fields using it, values starting with
+// $ are replaced by the directive argument of that name e.g. $name.
+type DirectiveMapping struct {
+	Directive string
+	Name      string
+	Tags      map[string]string
+}
+
+// MapDirectives adds the directive hooks of directive mappings to the
+// schema.
+func (s *Schema) MapDirectives(mappings ...DirectiveMapping) error {
+	for _, mapping := range mappings {
+		if mapping.Directive == "" || (mapping.Name == "" && len(mapping.Tags) == 0) {
+			return fmt.Errorf("directive mapping %q requires a directive name and a name or tags", mapping.Directive)
+		}
+		s.DirectiveHooks[s.directiveName(mapping.Directive)] = mapping.hook
+	}
+	return nil
+}
+
+// directiveName returns the schema name of a directive matched case
+// insensitively, config file keys are lower cased.
+func (s *Schema) directiveName(name string) string {
+	if _, ok := s.AstSchema.Directives[name]; ok {
+		return name
+	}
+	for directiveName := range s.AstSchema.Directives {
+		if strings.EqualFold(directiveName, name) {
+			return directiveName
+		}
+	}
+	return name
+}
+
+func (m DirectiveMapping) hook(directive *ast.Directive, override *FieldOverride) error {
+	if m.Name != "" {
+		name, err := mappingValue(directive, m.Name)
+		if err != nil {
+			return err
+		}
+		override.Name = name
+	}
+	keys := make([]string, 0, len(m.Tags))
+	for key := range m.Tags {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		value, err := mappingValue(directive, m.Tags[key])
+		if err != nil {
+			return err
+		}
+		override.SetTag(key, value)
+	}
+	return nil
+}
+
+// mappingValue returns the value of a directive mapping, the argument of
+// the directive is returned for $ values.
+func mappingValue(directive *ast.Directive, value string) (string, error) {
+	if !strings.HasPrefix(value, "$") {
+		return value, nil
+	}
+	argument := directiveArgument(directive, value[1:])
+	if argument == "" {
+		return "", fmt.Errorf("@%s requires %s", directive.Name, value[1:])
+	}
+	return argument, nil
+}
+
+// SetTag sets the value of a struct tag.
+func (o *FieldOverride) SetTag(key, value string) {
+	for i, tag := range o.Tags {
+		if tag.Key == key {
+			o.Tags[i].Value = value
+			return
+		}
+	}
+	o.Tags = append(o.Tags, StructTag{Key: key, Value: value})
+}
+
+// directiveArgument returns the raw value of a directive argument.
+func directiveArgument(directive *ast.Directive, name string) string {
+	argument := directive.Arguments.ForName(name)
+	if argument == nil || argument.Value == nil {
+		return ""
+	}
+	return argument.Value.Raw
+}
+
+// fieldOverride returns the struct field overrides of a graphql field
+// after running the directive hooks of its directives.
+func (s *Schema) fieldOverride(field *ast.FieldDefinition) (*FieldOverride, error) {
+	override := &FieldOverride{
+		Name: strcase.ToCamel(field.Name),
+		Tags: []StructTag{{Key: "json", Value: field.Name}},
+	}
+	for _, directive := range field.Directives {
+		hook, ok := s.DirectiveHooks[directive.Name]
+		if !ok {
+			continue
+		}
+		if err := hook(directive, override); err != nil {
+			return nil, fmt.Errorf("field %s: %w", field.Name, err)
+		}
+	}
+	return override, nil
+}
+
+// goFieldName returns the Go name of the struct field of a graphql field.
+func goFieldName(schema *Schema, field *ast.FieldDefinition) (string, error) {
+	override, err := schema.fieldOverride(field)
+	if err != nil {
+		return "", err
+	}
+	return override.Name, nil
+}
+
+// goFieldTag returns the struct tag of the struct field of a graphql
+// field.
+func goFieldTag(schema *Schema, field *ast.FieldDefinition) (string, error) {
+	override, err := schema.fieldOverride(field)
+	if err != nil {
+		return "", err
+	}
+	tags := []string{}
+	for _, tag := range override.Tags {
+		tags = append(tags, tag.Key+":"+strconv.Quote(tag.Value))
+	}
+	return "`" + strings.Join(tags, " ") + "`", nil
+}
+
+// deprecationReason returns the reason of the @deprecated directive.
+func deprecationReason(directives ast.DirectiveList) (string, bool) {
+	directive := directives.ForName("deprecated")
+	if directive == nil {
+		return "", false
+	}
+	if reason := strings.TrimSpace(directiveArgument(directive, "reason")); reason != "" {
+		return reason, true
+	}
+	return defaultDeprecationReason, true
+}
+
+// isDeprecated returns true if the directives contain @deprecated.
+func isDeprecated(directives ast.DirectiveList) bool {
+	_, ok := deprecationReason(directives)
+	return ok
+}
+
+// deprecationGoComment returns the Deprecated paragraph of a doc comment,
+// it is empty unless the directives contain @deprecated.
+func deprecationGoComment(directives ast.DirectiveList) string {
+	reason, ok := deprecationReason(directives)
+	if !ok {
+		return ""
+	}
+	return "//\n" + commentLines("Deprecated: "+reason)
+}
+
+// argumentsGoComment returns the doc comment lines of the deprecated
+// arguments.
+func argumentsGoComment(arguments ast.ArgumentDefinitionList) string {
+	comment := ""
+	for _, argument := range arguments {
+		if reason, ok := deprecationReason(argument.Directives); ok {
+			comment += commentLines(fmt.Sprintf("The %s argument is deprecated: %s", argument.Name, reason))
+		}
+	}
+	return comment
+}
+
+func commentLines(text string) string {
+	comment := ""
+	for _, line := range strings.Split(text, "\n") {
+		comment += strings.TrimRight("// "+strings.TrimSpace(line), " ") + "\n"
+	}
+	return comment
+}
+
+// ExcludeDeprecated removes the deprecated fields, enum values, optional
+// input fields and optional arguments from the schema.
+func (s *Schema) ExcludeDeprecated() {
+	for name, definition := range s.AstSchema.Types {
+		if strings.HasPrefix(name, "__") {
+			continue
+		}
+		if len(definition.Fields) > 0 {
+			definition.Fields = excludeDeprecatedFields(definition.Fields, definition.Kind == ast.InputObject)
+		}
+		if len(definition.EnumValues) > 0 {
+			enumValues := ast.EnumValueList{}
+			for _, value := range definition.EnumValues {
+				if !isDeprecated(value.Directives) {
+					enumValues = append(enumValues, value)
+				}
+			}
+			definition.EnumValues = enumValues
+		}
+	}
+	if query, ok := s.AstSchema.Types["Query"]; ok {
+		s.Queries = query.Fields
+	}
+	if mutation, ok := s.AstSchema.Types["Mutation"]; ok {
+		s.Mutations = mutation.Fields
+	}
+	if subscription, ok := s.AstSchema.Types["Subscription"]; ok {
+		s.Subscriptions = subscription.Fields
+	}
+}
+
+func excludeDeprecatedFields(fields ast.FieldList, input bool) ast.FieldList {
+	included := ast.FieldList{}
+	for _, field := range fields {
+		// required input fields can not be left out of inputs.
+		if isDeprecated(field.Directives) && (!input || !field.Type.NonNull) {
+			continue
+		}
+		arguments := ast.ArgumentDefinitionList{}
+		for _, argument := range field.Arguments {
+			// required arguments can not be left out of requests.
+			if isDeprecated(argument.Directives) && !argument.Type.NonNull {
+				continue
+			}
+			arguments = append(arguments, argument)
+		}
+		field.Arguments = arguments
+		included = append(included, field)
+	}
+	return included
+}
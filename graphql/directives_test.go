@@ -0,0 +1,274 @@
+package graphql
+
+import (
+	"errors"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/vektah/gqlparser"
+	gqlast "github.com/vektah/gqlparser/ast"
+)
+
+func TestDeprecationGoComment(t *testing.T) {
+	tests := []struct {
+		name, schema, want string
+	}{
+		{"not deprecated", `type Query { a: String }`, ""},
+		{"default reason", `type Query { a: String @deprecated }`, "//\n// Deprecated: No longer supported\n"},
+		{"reason", `type Query { a: String @deprecated(reason: "use b") }`, "//\n// Deprecated: use b\n"},
+		{"multiline reason", `type Query { a: String @deprecated(reason: """use b
+  or c""") }`, "//\n// Deprecated: use b\n// or c\n"},
+		{"blank reason", `type Query { a: String @deprecated(reason: " ") }`, "//\n// Deprecated: No longer supported\n"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			schema, err := gqlparser.LoadSchema(&gqlast.Source{Input: test.schema})
+			if err != nil {
+				t.Fatal(err)
+			}
+			field := schema.Query.Fields.ForName("a")
+			if got := deprecationGoComment(field.Directives); got != test.want {
+				t.Errorf("got %q, want %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestFieldOverride(t *testing.T) {
+	astSchema, err := gqlparser.LoadSchema(&gqlast.Source{Input: `
+directive @goField(name: String) on FIELD_DEFINITION
+directive @goTag(key: String!, value: String) on FIELD_DEFINITION
+directive @column(name: String!) on FIELD_DEFINITION
+
+type Query {
+  plain: String
+  renamed: String @goField(name: "Other")
+  tagged: String @goTag(key: "db", value: "tagged_at") @goTag(key: "db", value: "tagged")
+  jsonTag: String @goTag(key: "json", value: "jsonTag,omitempty")
+  column: String @column(name: "col")
+  broken: String @goTag(key: "")
+}
+`})
+	if err != nil {
+		t.Fatal(err)
+	}
+	schema := parseSchema(NewSchema(astSchema))
+	schema.DirectiveHooks["column"] = func(directive *gqlast.Directive, override *FieldOverride) error {
+		override.SetTag("db", directiveArgument(directive, "name"))
+		return nil
+	}
+	tests := []struct {
+		field, name, tag string
+	}{
+		{"plain", "Plain", "`json:\"plain\"`"},
+		{"renamed", "Other", "`json:\"renamed\"`"},
+		{"tagged", "Tagged", "`json:\"tagged\" db:\"tagged\"`"},
+		{"jsonTag", "JsonTag", "`json:\"jsonTag,omitempty\"`"},
+		{"column", "Column", "`json:\"column\" db:\"col\"`"},
+	}
+	for _, test := range tests {
+		field := schema.AstSchema.Query.Fields.ForName(test.field)
+		name, err := goFieldName(schema, field)
+		if err != nil {
+			t.Fatal(err)
+		}
+		tag, err := goFieldTag(schema, field)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if name != test.name || tag != test.tag {
+			t.Errorf("%s: got %s %s, want %s %s", test.field, name, tag, test.name, test.tag)
+		}
+	}
+	if _, err := goFieldTag(schema, schema.AstSchema.Query.Fields.ForName("broken")); err == nil || !strings.Contains(err.Error(), "field broken: @goTag requires a key") {
+		t.Errorf("got error %v, want the missing key", err)
+	}
+
+	schema.DirectiveHooks["column"] = func(*gqlast.Directive, *FieldOverride) error {
+		return errors.New("unsupported")
+	}
+	if _, err := goFieldName(schema, schema.AstSchema.Query.Fields.ForName("column")); err == nil {
+		t.Error("the hook error was ignored")
+	}
+}
+
+func TestMapDirectives(t *testing.T) {
+	astSchema, gqlErr := gqlparser.LoadSchema(&gqlast.Source{Input: `
+directive @column(name: String) on FIELD_DEFINITION
+directive @goRename(to: String!) on FIELD_DEFINITION
+directive @secret on FIELD_DEFINITION
+
+type Query {
+  column: String @column(name: "col")
+  renamed: String @goRename(to: "Other") @secret
+  unnamed: String @column
+}
+`})
+	if gqlErr != nil {
+		t.Fatal(gqlErr)
+	}
+	schema := parseSchema(NewSchema(astSchema))
+	err := schema.MapDirectives(
+		DirectiveMapping{Directive: "column", Tags: map[string]string{"db": "$name", "xml": "$name"}},
+		// config file keys are lower cased.
+		DirectiveMapping{Directive: "gorename", Name: "$to"},
+		DirectiveMapping{Directive: "secret", Tags: map[string]string{"json": "-"}},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		field, name, tag string
+	}{
+		{"column", "Column", "`json:\"column\" db:\"col\" xml:\"col\"`"},
+		{"renamed", "Other", "`json:\"-\"`"},
+	}
+	for _, test := range tests {
+		field := schema.AstSchema.Query.Fields.ForName(test.field)
+		name, err := goFieldName(schema, field)
+		if err != nil {
+			t.Fatal(err)
+		}
+		tag, err := goFieldTag(schema, field)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if name != test.name || tag != test.tag {
+			t.Errorf("%s: got %s %s, want %s %s", test.field, name, tag, test.name, test.tag)
+		}
+	}
+	if _, err := goFieldTag(schema, schema.AstSchema.Query.Fields.ForName("unnamed")); err == nil || !strings.Contains(err.Error(), "field unnamed: @column requires name") {
+		t.Errorf("got error %v, want the missing argument", err)
+	}
+	if err := schema.MapDirectives(DirectiveMapping{Directive: "secret"}); err == nil {
+		t.Error("the mapping without name and tags was accepted")
+	}
+}
+
+// deprecatedDeclarations returns the deprecation notices of the
+// generated declarations by name, a notice only counts when it is the last
+// paragraph of the doc comment.
+func deprecatedDeclarations(t *testing.T, filename string) map[string]string {
+	t.Helper()
+	file, err := parser.ParseFile(token.NewFileSet(), filename, nil, parser.ParseComments)
+	if err != nil {
+		t.Fatal(err)
+	}
+	deprecated := map[string]string{}
+	add := func(name string, doc *ast.CommentGroup) {
+		if doc == nil {
+			return
+		}
+		paragraphs := strings.Split(strings.TrimSpace(doc.Text()), "\n\n")
+		if notice := paragraphs[len(paragraphs)-1]; strings.HasPrefix(notice, "Deprecated: ") {
+			deprecated[name] = strings.TrimPrefix(notice, "Deprecated: ")
+		}
+	}
+	for _, declaration := range file.Decls {
+		switch declaration := declaration.(type) {
+		case *ast.FuncDecl:
+			name := declaration.Name.Name
+			if declaration.Recv != nil {
+				receiver := declaration.Recv.List[0].Type
+				if star, ok := receiver.(*ast.StarExpr); ok {
+					receiver = star.X
+				}
+				name = receiver.(*ast.Ident).Name + "." + name
+			}
+			add(name, declaration.Doc)
+		case *ast.GenDecl:
+			for _, spec := range declaration.Specs {
+				switch spec := spec.(type) {
+				case *ast.ValueSpec:
+					add(spec.Names[0].Name, spec.Doc)
+				case *ast.TypeSpec:
+					structType, ok := spec.Type.(*ast.StructType)
+					if !ok {
+						continue
+					}
+					for _, field := range structType.Fields.List {
+						if len(field.Names) > 0 {
+							add(spec.Name.Name+"."+field.Names[0].Name, field.Doc)
+						}
+					}
+				}
+			}
+		}
+	}
+	return deprecated
+}
+
+func TestGeneratedDeprecations(t *testing.T) {
+	dir := generateTestClient(t, "testdata/directives.graphql", map[string]string{"client_test.go": "testdata/directives_client_test.go"})
+	want := map[string]string{
+		"RoleMember":             "use USER",
+		"User.Name":              "use fullName",
+		"User.Nickname":          "No longer supported",
+		"UserFilter.LegacyRole":  "use role",
+		"UserFilter.LegacyGroup": "groups are ignored",
+		"UserSelection.Name":     "use fullName",
+		"UserSelection.Nickname": "No longer supported",
+		"Query.Users":            "use search instead",
+		"Query.SelectUsers":      "use search instead",
+		"BatchQuery.Users":       "use search instead",
+		"BatchQuery.SelectUsers": "use search instead",
+	}
+	got := deprecatedDeclarations(t, filepath.Join(dir, "client.go"))
+	for name, reason := range want {
+		if got[name] != reason {
+			t.Errorf("%s: got deprecation %q, want %q", name, got[name], reason)
+		}
+	}
+	for name := range got {
+		if _, ok := want[name]; !ok {
+			t.Errorf("%s is deprecated", name)
+		}
+	}
+	client, err := os.ReadFile(filepath.Join(dir, "client.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(client), "// User returns a user by id.\n// The includeDrafts argument is deprecated: drafts are always included\nfunc (q *Query) User(") {
+		t.Error("the deprecated argument is not documented")
+	}
+	runGo(t, dir, "vet", ".")
+	runGo(t, dir, "test", "-count=1", ".")
+}
+
+func TestGeneratedExcludeDeprecated(t *testing.T) {
+	schema, err := LoadGraphqlSchema("testdata/directives.graphql")
+	if err != nil {
+		t.Fatal(err)
+	}
+	schema.ExcludeDeprecated()
+	dir := t.TempDir()
+	if err := GenerateClient(schema, dir); err != nil {
+		t.Fatal(err)
+	}
+	writeTestModule(t, dir, nil)
+	// required input fields are kept like required arguments.
+	deprecated := deprecatedDeclarations(t, filepath.Join(dir, "client.go"))
+	if len(deprecated) != 1 || deprecated["UserFilter.LegacyGroup"] != "groups are ignored" {
+		t.Errorf("got deprecated declarations %v", deprecated)
+	}
+	client, err := os.ReadFile(filepath.Join(dir, "client.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, excluded := range []string{"RoleMember", "Nickname", "LegacyRole", "func (q *Query) Users(", "includeDrafts"} {
+		if strings.Contains(string(client), excluded) {
+			t.Errorf("the generated client contains %s", excluded)
+		}
+	}
+	for _, included := range []string{"RoleUser", "DisplayName", "LegacyGroup", "func (q *Query) User(ctx context.Context, id string, gqlFields string)"} {
+		if !strings.Contains(string(client), included) {
+			t.Errorf("the generated client does not contain %s", included)
+		}
+	}
+	runGo(t, dir, "vet", ".")
+}
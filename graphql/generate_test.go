@@ -0,0 +1,145 @@
+package graphql
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+// clientRequirements are the modules required by the generated clients
+// and their tests, they are only added to the go.mod of a generated client
+// when imported.
+var clientRequirements = map[string]string{
+	"github.com/machinebox/graphql": "v0.2.2",
+	"github.com/gorilla/websocket":  "v1.5.0",
+}
+
+// generateTestClient generates the client of a schema file in a module of
+// its own, the files are copied into the module by destination name.
+func generateTestClient(t *testing.T, schemaFile string, files map[string]string, scalars ...ScalarMapping) string {
+	t.Helper()
+	dir := t.TempDir()
+	if err := GenerateGoSDK(schemaFile, dir, scalars...); err != nil {
+		t.Fatal(err)
+	}
+	writeTestModule(t, dir, files)
+	return dir
+}
+
+// generateOperationsTestClient generates the client of a schema file
+// together with the functions of the operation files in a module of its
+// own, the files are copied into the module by destination name.
+func generateOperationsTestClient(t *testing.T, schemaFile string, operationFiles []string, files map[string]string) string {
+	t.Helper()
+	dir := generateTestClient(t, schemaFile, nil)
+	if err := GenerateOperations(schemaFile, operationFiles, dir); err != nil {
+		t.Fatal(err)
+	}
+	writeTestModule(t, dir, files)
+	return dir
+}
+
+// writeTestModule copies the files into the directory of a generated
+// client and writes its go.mod.
+func writeTestModule(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := os.MkdirAll(filepath.Dir(filepath.Join(dir, name)), 0700); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, name), data, 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	sources, err := filepath.Glob(filepath.Join(dir, "*.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	goMod := "module client\n\ngo 1.16\n"
+	for path, version := range clientRequirements {
+		for _, source := range sources {
+			data, err := os.ReadFile(source)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if bytes.Contains(data, []byte(`"`+path+`"`)) {
+				goMod += "\nrequire " + path + " " + version + "\n"
+				break
+			}
+		}
+	}
+	if err := os.WriteFile(filepath.Join(dir, "go.mod"), []byte(goMod), 0600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// runGo runs the go command in the directory of a generated client.
+func runGo(t *testing.T, dir string, args ...string) {
+	t.Helper()
+	goBin, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("go is not installed")
+	}
+	cmd := exec.Command(goBin, args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "GOFLAGS=-mod=mod", "GOWORK=off")
+	if output, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("go %v: %v\n%s", args, err, output)
+	}
+}
+
+// testClient vets the client generated from a schema file and runs the
+// tests of the test file against it, if any.
+func testClient(t *testing.T, schemaFile, testFile string, scalars ...ScalarMapping) {
+	t.Helper()
+	files := map[string]string{}
+	if testFile != "" {
+		files["client_test.go"] = testFile
+	}
+	dir := generateTestClient(t, schemaFile, files, scalars...)
+	runGo(t, dir, "vet", ".")
+	if testFile != "" {
+		runGo(t, dir, "test", "-count=1", ".")
+	}
+}
+
+func TestGenerateSampleClient(t *testing.T) {
+	testClient(t, "../sample.graphql", "")
+}
+
+func TestGeneratedSubscriptions(t *testing.T) {
+	testClient(t, "testdata/subscriptions.graphql", "testdata/subscriptions_client_test.go")
+
+	// the WebSocket client of subscriptions only uses the standard library.
+	dir := generateTestClient(t, "testdata/subscriptions.graphql", nil)
+	goMod, err := os.ReadFile(filepath.Join(dir, "go.mod"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(goMod, []byte("require")) {
+		t.Errorf("got go.mod %s, want no requirements", goMod)
+	}
+}
+
+func TestGeneratedErrors(t *testing.T) {
+	testClient(t, "testdata/errors.graphql", "testdata/errors_client_test.go")
+}
+
+func TestGeneratedTransport(t *testing.T) {
+	dir := generateTestClient(t, "testdata/errors.graphql", map[string]string{"client_test.go": "testdata/transport_client_test.go"})
+	goMod, err := os.ReadFile(filepath.Join(dir, "go.mod"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(goMod, []byte("require")) {
+		t.Errorf("the generated client has dependencies:\n%s", goMod)
+	}
+	runGo(t, dir, "vet", ".")
+	runGo(t, dir, "test", "-count=1", ".")
+}
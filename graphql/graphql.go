@@ -3,14 +3,18 @@ package graphql
 import (
 	"bytes"
 	_ "embed"
+	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
 	"strings"
 	"text/template"
 
+	"github.com/fatih/color"
 	"github.com/iancoleman/strcase"
 	"github.com/vektah/gqlparser"
 	"github.com/vektah/gqlparser/ast"
+	"github.com/wisdommatt/sdkgen/pkg/log"
 	"golang.org/x/tools/imports"
 )
 
@@ -23,20 +27,37 @@ type Schema struct {
 	Scalars       map[string]*ast.Definition
 	Unions        map[string]*ast.Definition
 	Enums         map[string]*ast.Definition
+	Interfaces    map[string]*ast.Definition
 	Mutations     []*ast.FieldDefinition
 	Queries       []*ast.FieldDefinition
 	Subscriptions []*ast.FieldDefinition
+	// ScalarMappings contains the Go types of the mapped scalars.
+	ScalarMappings map[string]ScalarMapping
+	// Imports contains the import paths of the mapped scalars together
+	// with their import alias.
+	Imports map[string]string
+	// DirectiveHooks contains the hooks of the field directives that
+	// override the generated struct fields by directive name.
+	DirectiveHooks map[string]DirectiveHook
+	// StrictEnums makes the generated enums reject the values that are
+	// not defined in the schema when they are marshalled or unmarshalled.
+	StrictEnums bool
 }
 
 // NewSchema creates a new schema from an ast schema object.
 func NewSchema(astSchema *ast.Schema) *Schema {
 	return &Schema{
-		AstSchema: astSchema,
-		Objects:   make(map[string]*ast.Definition),
-		Inputs:    make(map[string]*ast.Definition),
-		Scalars:   make(map[string]*ast.Definition),
-		Unions:    make(map[string]*ast.Definition),
-		Enums:     make(map[string]*ast.Definition),
+		AstSchema:  astSchema,
+		Objects:    make(map[string]*ast.Definition),
+		Inputs:     make(map[string]*ast.Definition),
+		Scalars:    make(map[string]*ast.Definition),
+		Unions:     make(map[string]*ast.Definition),
+		Enums:      make(map[string]*ast.Definition),
+		Interfaces: make(map[string]*ast.Definition),
+
+		ScalarMappings: make(map[string]ScalarMapping),
+		Imports:        make(map[string]string),
+		DirectiveHooks: defaultDirectiveHooks(),
 	}
 }
 
@@ -55,6 +76,12 @@ var (
 		"Email":   "string",
 	}
 
+	// schemaFileExtensions are the extensions of the schema files loaded
+	// from directories.
+	schemaFileExtensions = map[string]bool{
+		".graphql": true, ".graphqls": true, ".gql": true,
+	}
+
 	builtInTypesMap = map[string]string{
 		"int":     "0",
 		"string":  "",
@@ -63,52 +90,7 @@ var (
 	}
 
 	templateFuncs = template.FuncMap{
-		"extractFieldTypeName": func(schema *Schema, name string, typ *ast.Type, isObj ...interface{}) string {
-			fieldType := strings.ReplaceAll(typ.Name(), "!", "")
-
-			// checking if field type is an array.
-			if typ.Elem != nil {
-				if typeName, ok := graphqlDefaultFieldsMap[fieldType]; ok {
-					if typ.NonNull {
-						return "[]" + typeName
-					}
-					return "[]*" + typeName
-				}
-				// return field type as interface{} if initial field type
-				// is a graphql scalar.
-				if _, ok := schema.Scalars[fieldType]; ok {
-					return "[]interface{}"
-				}
-				// checking if the field type is a union type.
-				if _, ok := schema.Unions[fieldType]; isObj != nil && ok {
-					fieldType += "Instance"
-				}
-				if typ.Elem.NonNull {
-					return "[]" + strcase.ToCamel(fieldType)
-				}
-				return "[]*" + strcase.ToCamel(fieldType)
-			}
-
-			if typeName, ok := graphqlDefaultFieldsMap[fieldType]; ok {
-				if typ.NonNull {
-					return typeName
-				}
-				return "*" + typeName
-			}
-			// return field type as interface{} if initial field type
-			// is a graphql scalar.
-			if _, ok := schema.Scalars[fieldType]; ok {
-				return "interface{}"
-			}
-			// checking if the field type is a union type.
-			if _, ok := schema.Unions[fieldType]; isObj != nil && ok {
-				fieldType += "Instance"
-			}
-			if typ.NonNull {
-				return strcase.ToCamel(fieldType)
-			}
-			return "*" + strcase.ToCamel(fieldType)
-		},
+		"extractFieldTypeName": extractFieldTypeName,
 		"toCamelCase": func(str string) string {
 			if strings.HasPrefix(str, "__") {
 				return str
@@ -142,15 +124,30 @@ var (
 			}
 			return "nil"
 		},
-		"extractGoComment": func(name, description string) string {
-			description = strings.TrimSpace(description)
-			if description == "" {
-				return ""
-			}
-			name = strcase.ToCamel(name)
-			comment := fmt.Sprintf("// %s %s \n", name, description)
-			return comment
+		// extractGoComment accepts the directives and the arguments of a
+		// field to add the notices of deprecated fields and arguments.
+		"extractGoComment": func(name, description string, extras ...interface{}) string {
+			comment := ""
+			if description = strings.TrimSpace(description); description != "" {
+				comment = fmt.Sprintf("// %s %s \n", strcase.ToCamel(name), description)
+			}
+			deprecation := ""
+			for _, extra := range extras {
+				switch extra := extra.(type) {
+				case ast.ArgumentDefinitionList:
+					comment += argumentsGoComment(extra)
+				case ast.DirectiveList:
+					deprecation += deprecationGoComment(extra)
+				}
+			}
+			if comment == "" {
+				return strings.TrimPrefix(deprecation, "//\n")
+			}
+			return comment + deprecation
 		},
+		"deprecationGoComment": deprecationGoComment,
+		"goFieldName":          goFieldName,
+		"goFieldTag":           goFieldTag,
 		"extractUnionFields": func(schema *Schema, union *ast.Definition) []string {
 			checker := map[string]bool{}
 			fields := []string{}
@@ -165,10 +162,93 @@ var (
 			}
 			return fields
 		},
+		"selectionTypeName":    selectionTypeName,
+		"selectorName":         selectorName,
+		"argumentName":         argumentName,
+		"possibleTypes":        possibleTypes,
+		"interfaceFields":      interfaceFields,
+		"implementedFieldType": implementedFieldType,
+		"connection":           connection,
+		"enumValueName":        enumValueName,
+		"goTypeName":           goTypeName,
 	}
 )
 
-// LoadGraphqlSchema loads graphql schemas from graphql schema files.
+// goTypeName returns the Go name of a graphql type, it is used for the
+// declarations of the generated types and all their references.
+func goTypeName(name string) string {
+	return strcase.ToCamel(name)
+}
+
+// enumValueName returns the Go name of an enum value, SCREAMING_CASE
+// values are lower cased first so that IN_PROGRESS is InProgress and not
+// INPROGRESS.
+func enumValueName(name string) string {
+	if strings.ToUpper(name) == name {
+		name = strings.ToLower(name)
+	}
+	return strcase.ToCamel(name)
+}
+
+// extractFieldTypeName returns the Go type of a field type, union types
+// are returned as their instance types when isObj is set.
+func extractFieldTypeName(schema *Schema, name string, typ *ast.Type, isObj ...interface{}) string {
+	fieldType := strings.ReplaceAll(typ.Name(), "!", "")
+
+	// checking if field type is an array.
+	if typ.Elem != nil {
+		if typeName, ok := schema.scalarTypeName(fieldType); ok {
+			if typ.NonNull {
+				return "[]" + typeName
+			}
+			return "[]*" + typeName
+		}
+		// return field type as interface{} if initial field type
+		// is a graphql scalar.
+		if _, ok := schema.Scalars[fieldType]; ok {
+			return "[]interface{}"
+		}
+		// checking if the field type is a union type.
+		if _, ok := schema.Unions[fieldType]; isObj != nil && ok {
+			fieldType += "Instance"
+		}
+		// interface values are decoded by their __typename.
+		if _, ok := schema.Interfaces[fieldType]; ok {
+			fieldType += "Instance"
+		}
+		if typ.Elem.NonNull {
+			return "[]" + goTypeName(fieldType)
+		}
+		return "[]*" + goTypeName(fieldType)
+	}
+
+	if typeName, ok := schema.scalarTypeName(fieldType); ok {
+		if typ.NonNull {
+			return typeName
+		}
+		return "*" + typeName
+	}
+	// return field type as interface{} if initial field type
+	// is a graphql scalar.
+	if _, ok := schema.Scalars[fieldType]; ok {
+		return "interface{}"
+	}
+	// checking if the field type is a union type.
+	if _, ok := schema.Unions[fieldType]; isObj != nil && ok {
+		fieldType += "Instance"
+	}
+	// interface values are decoded by their __typename.
+	if _, ok := schema.Interfaces[fieldType]; ok {
+		fieldType += "Instance"
+	}
+	if typ.NonNull {
+		return goTypeName(fieldType)
+	}
+	return "*" + goTypeName(fieldType)
+}
+
+// LoadGraphqlSchema loads graphql schemas from graphql schema files,
+// introspection result JSON files are also accepted.
 func LoadGraphqlSchema(filenames ...string) (*Schema, error) {
 	sources := []*ast.Source{}
 	for _, filename := range filenames {
@@ -176,10 +256,77 @@ func LoadGraphqlSchema(filenames ...string) (*Schema, error) {
 		if err != nil {
 			return nil, err
 		}
+		if IsIntrospectionResult(fileContents) {
+			source, err := IntrospectionSource(filename, fileContents)
+			if err != nil {
+				return nil, err
+			}
+			sources = append(sources, source)
+			continue
+		}
 		sources = append(sources, &ast.Source{
+			Name:  filename,
 			Input: string(fileContents),
 		})
 	}
+	return LoadGraphqlSources(sources...)
+}
+
+// ExpandSchemaFiles returns the schema files matching the paths, paths
+// can be files, globs or directories which are searched recursively for
+// .graphql, .graphqls and .gql files.
+func ExpandSchemaFiles(paths ...string) ([]string, error) {
+	checker := map[string]bool{}
+	filenames := []string{}
+	add := func(filename string) {
+		if !checker[filename] {
+			checker[filename] = true
+			filenames = append(filenames, filename)
+		}
+	}
+	for _, path := range paths {
+		matches := []string{path}
+		if strings.ContainsAny(path, "*?[") {
+			var err error
+			matches, err = filepath.Glob(path)
+			if err != nil {
+				return nil, err
+			}
+			if len(matches) == 0 {
+				return nil, fmt.Errorf("no schema files match %s", path)
+			}
+		}
+		for _, match := range matches {
+			info, err := os.Stat(match)
+			if err != nil {
+				return nil, err
+			}
+			if !info.IsDir() {
+				add(match)
+				continue
+			}
+			err = filepath.Walk(match, func(filename string, info os.FileInfo, err error) error {
+				if err != nil {
+					return err
+				}
+				if !info.IsDir() && schemaFileExtensions[strings.ToLower(filepath.Ext(filename))] {
+					add(filename)
+				}
+				return nil
+			})
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	if len(filenames) == 0 {
+		return nil, errors.New("no schema files found")
+	}
+	return filenames, nil
+}
+
+// LoadGraphqlSources loads a graphql schema from schema sources.
+func LoadGraphqlSources(sources ...*ast.Source) (*Schema, error) {
 	astSchema, err := gqlparser.LoadSchema(sources...)
 	if err != nil {
 		return nil, err
@@ -214,6 +361,9 @@ func parseSchema(schema *Schema) *Schema {
 		case ast.Union:
 			schema.Unions[key] = typ
 
+		case ast.Interface:
+			schema.Interfaces[key] = typ
+
 		case ast.Enum:
 			schema.Enums[key] = typ
 
@@ -227,13 +377,27 @@ func parseSchema(schema *Schema) *Schema {
 	return schema
 }
 
-// GenerateGoSDK generates a Go graphql sdk client from schema file.
-func GenerateGoSDK(schemaFile string, outputDirectory string) error {
+// GenerateGoSDK generates a Go graphql sdk client from schema file, the
+// custom scalars are generated as interface{} unless they are mapped to a
+// Go type.
+func GenerateGoSDK(schemaFile string, outputDirectory string, scalars ...ScalarMapping) error {
 	schema, err := LoadGraphqlSchema(schemaFile)
 	if err != nil {
 		return err
 	}
-	err = os.MkdirAll(outputDirectory, 0700)
+	err = schema.MapScalars(scalars...)
+	if err != nil {
+		return err
+	}
+	return GenerateClient(schema, outputDirectory)
+}
+
+// GenerateClient generates a Go graphql sdk client from a loaded schema.
+func GenerateClient(schema *Schema, outputDirectory string) error {
+	if unmapped := schema.UnmappedScalars(); len(unmapped) > 0 {
+		log.Println(color.FgYellow, "WARNING", "scalars without a Go type mapping are generated as interface{}:", strings.Join(unmapped, ", "))
+	}
+	err := os.MkdirAll(outputDirectory, 0700)
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,195 @@
+package graphql
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/vektah/gqlparser/ast"
+)
+
+func TestExpandSchemaFiles(t *testing.T) {
+	tests := map[string]struct {
+		paths []string
+		want  []string
+	}{
+		"directory": {
+			paths: []string{"testdata/split"},
+			want:  []string{"testdata/split/posts/post.gql", "testdata/split/schema.graphql", "testdata/split/users/user.graphqls"},
+		},
+		"glob": {
+			paths: []string{"testdata/split/*/*"},
+			want:  []string{"testdata/split/posts/post.gql", "testdata/split/users/user.graphqls"},
+		},
+		"files are only added once": {
+			paths: []string{"testdata/split/schema.graphql", "testdata/split/users", "testdata/split/*.graphql"},
+			want:  []string{"testdata/split/schema.graphql", "testdata/split/users/user.graphqls"},
+		},
+		"files are added whatever their extension": {
+			paths: []string{"testdata/split/README.md"},
+			want:  []string{"testdata/split/README.md"},
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := ExpandSchemaFiles(test.paths...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			want := []string{}
+			for _, filename := range test.want {
+				want = append(want, filepath.FromSlash(filename))
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("got files %v, want %v", got, want)
+			}
+		})
+	}
+
+	errorTests := map[string]struct {
+		paths []string
+		want  string
+	}{
+		"glob without matches": {paths: []string{"testdata/split/*.gql"}, want: "no schema files match testdata/split/*.gql"},
+		"missing file":         {paths: []string{"testdata/missing.graphql"}, want: "no such file or directory"},
+		"empty directory":      {paths: []string{t.TempDir()}, want: "no schema files found"},
+	}
+	for name, test := range errorTests {
+		t.Run(name, func(t *testing.T) {
+			_, err := ExpandSchemaFiles(test.paths...)
+			if err == nil || !strings.Contains(err.Error(), test.want) {
+				t.Errorf("got error %v, want %q", err, test.want)
+			}
+		})
+	}
+}
+
+func TestLoadGraphqlSchemaExtensions(t *testing.T) {
+	files, err := ExpandSchemaFiles("testdata/split")
+	if err != nil {
+		t.Fatal(err)
+	}
+	schema, err := LoadGraphqlSchema(files...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	user := schema.Objects["User"]
+	if user == nil || user.Fields.ForName("name") == nil || user.Fields.ForName("posts") == nil {
+		t.Errorf("the User extension was not merged: %v", user)
+	}
+	queries := []string{}
+	for _, query := range schema.Queries {
+		if !strings.HasPrefix(query.Name, "__") {
+			queries = append(queries, query.Name)
+		}
+	}
+	if strings.Join(queries, ",") != "user,posts" {
+		t.Errorf("got queries %v, want the Query extension to be merged", queries)
+	}
+
+	// validation errors are reported with the name of the schema file.
+	_, err = LoadGraphqlSchema("testdata/split/users/user.graphqls")
+	if err == nil || !strings.Contains(err.Error(), "user.graphqls") {
+		t.Errorf("got error %v, want the name of the invalid file", err)
+	}
+}
+
+func TestGeneratedSplitSchema(t *testing.T) {
+	files, err := ExpandSchemaFiles("testdata/split")
+	if err != nil {
+		t.Fatal(err)
+	}
+	schema, err := LoadGraphqlSchema(files...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	if err := GenerateClient(schema, dir); err != nil {
+		t.Fatal(err)
+	}
+	writeTestModule(t, dir, map[string]string{"client_test.go": "testdata/split_client_test.go"})
+	runGo(t, dir, "vet", ".")
+	runGo(t, dir, "test", "-count=1", ".")
+}
+
+func TestEnumValueName(t *testing.T) {
+	tests := map[string]string{
+		"ACTIVE":      "Active",
+		"IN_PROGRESS": "InProgress",
+		"INPROGRESS":  "Inprogress",
+		"V2_API":      "V2Api",
+		"inProgress":  "InProgress",
+		"in_progress": "InProgress",
+		"Pending":     "Pending",
+	}
+	for name, want := range tests {
+		if got := enumValueName(name); got != want {
+			t.Errorf("enumValueName(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
+
+func TestGenerateClientEnums(t *testing.T) {
+	schema, err := LoadGraphqlSources(&ast.Source{Name: "schema.graphql", Input: `
+type Query {
+  status: Status
+}
+
+enum Status {
+  IN_PROGRESS
+  INPROGRESS
+  DONE
+}
+`})
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	if err := GenerateClient(schema, dir); err != nil {
+		t.Fatal(err)
+	}
+	client, err := os.ReadFile(filepath.Join(dir, "client.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		`StatusInProgress Status = "IN_PROGRESS"`,
+		`StatusInprogress Status = "INPROGRESS"`,
+		`StatusDone       Status = "DONE"`,
+		"case StatusInProgress, StatusInprogress, StatusDone:",
+	} {
+		if !strings.Contains(string(client), want) {
+			t.Errorf("the generated client does not contain %q", want)
+		}
+	}
+	// unknown values are accepted unless the enums are strict.
+	if strings.Contains(string(client), "is not a valid Status") {
+		t.Error("the generated enums are strict")
+	}
+	writeTestModule(t, dir, nil)
+	runGo(t, dir, "vet", ".")
+}
+
+func TestGeneratedStrictEnums(t *testing.T) {
+	schema, err := LoadGraphqlSchema("testdata/enums.graphql")
+	if err != nil {
+		t.Fatal(err)
+	}
+	schema.StrictEnums = true
+	dir := t.TempDir()
+	if err := GenerateClient(schema, dir); err != nil {
+		t.Fatal(err)
+	}
+	writeTestModule(t, dir, map[string]string{"client_test.go": "testdata/enums_client_test.go"})
+	runGo(t, dir, "vet", ".")
+	runGo(t, dir, "test", "-count=1", ".")
+}
+
+// TestGenerateClientTypeNames generates a client and operations from a
+// schema whose type names are not camel cased.
+func TestGenerateClientTypeNames(t *testing.T) {
+	dir := generateOperationsTestClient(t, "testdata/names.graphql", []string{"testdata/names_operations.graphql"}, nil)
+	runGo(t, dir, "vet", ".")
+}
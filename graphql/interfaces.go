@@ -0,0 +1,40 @@
+package graphql
+
+import (
+	"strings"
+
+	"github.com/vektah/gqlparser/ast"
+)
+
+// interfaceFields returns the fields of the interfaces implemented by an
+// object, fields declared by several interfaces are only returned once.
+func interfaceFields(schema *Schema, object *ast.Definition) []*ast.FieldDefinition {
+	checker := map[string]bool{}
+	fields := []*ast.FieldDefinition{}
+	for _, name := range object.Interfaces {
+		definition, ok := schema.Interfaces[name]
+		if !ok {
+			continue
+		}
+		for _, field := range definition.Fields {
+			if checker[field.Name] || strings.HasPrefix(field.Name, "_") || strings.HasPrefix(field.Type.Name(), "_") {
+				continue
+			}
+			checker[field.Name] = true
+			fields = append(fields, field)
+		}
+	}
+	return fields
+}
+
+// implementedFieldType returns the type of an object field as declared by
+// the interfaces of the object so that the generated accessors match the
+// interface methods, the field type is returned for other fields.
+func implementedFieldType(schema *Schema, object *ast.Definition, field *ast.FieldDefinition) *ast.Type {
+	for _, interfaceField := range interfaceFields(schema, object) {
+		if interfaceField.Name == field.Name {
+			return interfaceField.Type
+		}
+	}
+	return field.Type
+}
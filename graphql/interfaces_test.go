@@ -0,0 +1,56 @@
+package graphql
+
+import (
+	"testing"
+
+	"github.com/vektah/gqlparser"
+	"github.com/vektah/gqlparser/ast"
+)
+
+func TestInterfaceFields(t *testing.T) {
+	astSchema, err := gqlparser.LoadSchema(&ast.Source{Input: `
+type Query {
+  user: User
+}
+
+interface Node {
+  id: ID!
+  _internal: String
+}
+
+interface Named {
+  id: ID!
+  name: String
+}
+
+type User implements Node & Named {
+  id: ID!
+  name: String!
+  email: String
+  _internal: String
+}
+`})
+	if err != nil {
+		t.Fatal(err)
+	}
+	schema := parseSchema(NewSchema(astSchema))
+	user := schema.Objects["User"]
+
+	names := []string{}
+	for _, field := range interfaceFields(schema, user) {
+		names = append(names, field.Name)
+	}
+	if len(names) != 2 || names[0] != "id" || names[1] != "name" {
+		t.Errorf("got interface fields %v, want [id name]", names)
+	}
+	if typ := implementedFieldType(schema, user, user.Fields.ForName("name")); typ.String() != "String" {
+		t.Errorf("got name type %s, want the interface type String", typ)
+	}
+	if typ := implementedFieldType(schema, user, user.Fields.ForName("email")); typ.String() != "String" {
+		t.Errorf("got email type %s, want String", typ)
+	}
+}
+
+func TestGeneratedInterfaces(t *testing.T) {
+	testClient(t, "testdata/interfaces.graphql", "testdata/interfaces_client_test.go")
+}
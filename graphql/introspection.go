@@ -0,0 +1,414 @@
+package graphql
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/vektah/gqlparser/ast"
+)
+
+// IntrospectionQuery is the standard graphql introspection query.
+const IntrospectionQuery = `query IntrospectionQuery {
+  __schema {
+    queryType { name }
+    mutationType { name }
+    subscriptionType { name }
+    types { ...FullType }
+    directives {
+      name
+      description
+      locations
+      args { ...InputValue }
+    }
+  }
+}
+
+fragment FullType on __Type {
+  kind
+  name
+  description
+  fields(includeDeprecated: true) {
+    name
+    description
+    args { ...InputValue }
+    type { ...TypeRef }
+    isDeprecated
+    deprecationReason
+  }
+  inputFields { ...InputValue }
+  interfaces { ...TypeRef }
+  enumValues(includeDeprecated: true) {
+    name
+    description
+    isDeprecated
+    deprecationReason
+  }
+  possibleTypes { ...TypeRef }
+}
+
+fragment InputValue on __InputValue {
+  name
+  description
+  type { ...TypeRef }
+  defaultValue
+}
+
+fragment TypeRef on __Type {
+  kind
+  name
+  ofType {
+    kind
+    name
+    ofType {
+      kind
+      name
+      ofType {
+        kind
+        name
+        ofType {
+          kind
+          name
+          ofType {
+            kind
+            name
+            ofType {
+              kind
+              name
+              ofType {
+                kind
+                name
+              }
+            }
+          }
+        }
+      }
+    }
+  }
+}`
+
+var (
+	// preludeTypes are defined by the gqlparser prelude and are skipped
+	// when an introspection result is converted to SDL.
+	preludeTypes = map[string]bool{
+		"Int": true, "Float": true, "String": true, "Boolean": true, "ID": true,
+	}
+
+	preludeDirectives = map[string]bool{
+		"include": true, "skip": true, "deprecated": true,
+	}
+
+	// directiveLocations are the directive locations supported by the
+	// schema parser.
+	directiveLocations = map[string]bool{
+		"QUERY": true, "MUTATION": true, "SUBSCRIPTION": true, "FIELD": true,
+		"FRAGMENT_DEFINITION": true, "FRAGMENT_SPREAD": true, "INLINE_FRAGMENT": true,
+		"SCHEMA": true, "SCALAR": true, "OBJECT": true, "FIELD_DEFINITION": true,
+		"ARGUMENT_DEFINITION": true, "INTERFACE": true, "UNION": true, "ENUM": true,
+		"ENUM_VALUE": true, "INPUT_OBJECT": true, "INPUT_FIELD_DEFINITION": true,
+	}
+)
+
+type introspectionResult struct {
+	Data *struct {
+		Schema *introspectionSchema `json:"__schema"`
+	} `json:"data"`
+	Schema *introspectionSchema `json:"__schema"`
+	Errors []struct {
+		Message string `json:"message"`
+	} `json:"errors"`
+}
+
+type introspectionSchema struct {
+	QueryType        *introspectionTypeRef    `json:"queryType"`
+	MutationType     *introspectionTypeRef    `json:"mutationType"`
+	SubscriptionType *introspectionTypeRef    `json:"subscriptionType"`
+	Types            []introspectionType      `json:"types"`
+	Directives       []introspectionDirective `json:"directives"`
+}
+
+type introspectionType struct {
+	Kind          string                    `json:"kind"`
+	Name          string                    `json:"name"`
+	Description   string                    `json:"description"`
+	Fields        []introspectionField      `json:"fields"`
+	InputFields   []introspectionInputValue `json:"inputFields"`
+	Interfaces    []introspectionTypeRef    `json:"interfaces"`
+	EnumValues    []introspectionEnumValue  `json:"enumValues"`
+	PossibleTypes []introspectionTypeRef    `json:"possibleTypes"`
+}
+
+type introspectionField struct {
+	Name              string                    `json:"name"`
+	Description       string                    `json:"description"`
+	Args              []introspectionInputValue `json:"args"`
+	Type              introspectionTypeRef      `json:"type"`
+	IsDeprecated      bool                      `json:"isDeprecated"`
+	DeprecationReason *string                   `json:"deprecationReason"`
+}
+
+type introspectionInputValue struct {
+	Name         string               `json:"name"`
+	Description  string               `json:"description"`
+	Type         introspectionTypeRef `json:"type"`
+	DefaultValue *string              `json:"defaultValue"`
+}
+
+type introspectionEnumValue struct {
+	Name              string  `json:"name"`
+	Description       string  `json:"description"`
+	IsDeprecated      bool    `json:"isDeprecated"`
+	DeprecationReason *string `json:"deprecationReason"`
+}
+
+type introspectionDirective struct {
+	Name        string                    `json:"name"`
+	Description string                    `json:"description"`
+	Locations   []string                  `json:"locations"`
+	Args        []introspectionInputValue `json:"args"`
+}
+
+type introspectionTypeRef struct {
+	Kind   string                `json:"kind"`
+	Name   string                `json:"name"`
+	OfType *introspectionTypeRef `json:"ofType"`
+}
+
+// String returns the type reference in SDL notation e.g. [String!]!.
+func (t introspectionTypeRef) String() string {
+	if t.OfType == nil {
+		return t.Name
+	}
+	switch t.Kind {
+	case "NON_NULL":
+		return t.OfType.String() + "!"
+	case "LIST":
+		return "[" + t.OfType.String() + "]"
+	}
+	return t.Name
+}
+
+// IsIntrospectionResult returns true if the data is a JSON document, it
+// is used to tell introspection results apart from SDL schema files.
+func IsIntrospectionResult(data []byte) bool {
+	data = bytes.TrimSpace(data)
+	return len(data) > 0 && data[0] == '{'
+}
+
+// IntrospectionSource converts an introspection result into a SDL schema
+// source, both the raw __schema object and a full graphql response with
+// the __schema in data are accepted.
+func IntrospectionSource(name string, data []byte) (*ast.Source, error) {
+	result := introspectionResult{}
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, fmt.Errorf("%s: invalid introspection result: %w", name, err)
+	}
+	if len(result.Errors) > 0 {
+		return nil, fmt.Errorf("%s: introspection failed: %s", name, result.Errors[0].Message)
+	}
+	schema := result.Schema
+	if result.Data != nil && result.Data.Schema != nil {
+		schema = result.Data.Schema
+	}
+	if schema == nil {
+		return nil, fmt.Errorf("%s: introspection result has no __schema", name)
+	}
+	return &ast.Source{
+		Name:  name,
+		Input: schema.sdl(),
+	}, nil
+}
+
+// IntrospectEndpoint runs the introspection query against a graphql
+// endpoint and returns the schema as a SDL source.
+func IntrospectEndpoint(ctx context.Context, endpoint string, headers map[string]string) (*ast.Source, error) {
+	body, err := json.Marshal(map[string]string{"query": IntrospectionQuery})
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	data, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, err
+	}
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return nil, fmt.Errorf("%s: introspection failed with status %s", endpoint, res.Status)
+	}
+	return IntrospectionSource(endpoint, data)
+}
+
+// LoadGraphqlSchemaFromEndpoint loads a graphql schema by introspecting a
+// graphql endpoint.
+func LoadGraphqlSchemaFromEndpoint(ctx context.Context, endpoint string, headers map[string]string) (*Schema, error) {
+	source, err := IntrospectEndpoint(ctx, endpoint, headers)
+	if err != nil {
+		return nil, err
+	}
+	return LoadGraphqlSources(source)
+}
+
+// ParseHeader parses a "Key: Value" or "Key=Value" header.
+func ParseHeader(str string) (string, string, error) {
+	index := strings.IndexAny(str, ":=")
+	if index <= 0 {
+		return "", "", errors.New("invalid header " + str + ", expected Key: Value")
+	}
+	return strings.TrimSpace(str[:index]), strings.TrimSpace(str[index+1:]), nil
+}
+
+// sdl returns the schema definition language document of the schema.
+func (s *introspectionSchema) sdl() string {
+	builder := &strings.Builder{}
+	operations := []struct {
+		operation string
+		typeRef   *introspectionTypeRef
+		name      string
+	}{
+		{"query", s.QueryType, "Query"},
+		{"mutation", s.MutationType, "Mutation"},
+		{"subscription", s.SubscriptionType, "Subscription"},
+	}
+	customRoots := []string{}
+	for _, operation := range operations {
+		if operation.typeRef != nil && operation.typeRef.Name != operation.name {
+			customRoots = append(customRoots, operation.operation+": "+operation.typeRef.Name)
+		}
+	}
+	if len(customRoots) > 0 {
+		roots := []string{}
+		for _, operation := range operations {
+			if operation.typeRef != nil {
+				roots = append(roots, operation.operation+": "+operation.typeRef.Name)
+			}
+		}
+		builder.WriteString("schema {\n  " + strings.Join(roots, "\n  ") + "\n}\n\n")
+	}
+	for _, directive := range s.Directives {
+		if preludeDirectives[directive.Name] {
+			continue
+		}
+		locations := []string{}
+		for _, location := range directive.Locations {
+			if directiveLocations[location] {
+				locations = append(locations, location)
+			}
+		}
+		if len(locations) == 0 {
+			continue
+		}
+		writeDescription(builder, "", directive.Description)
+		builder.WriteString("directive @" + directive.Name + inputValuesSDL(directive.Args) + " on " + strings.Join(locations, " | ") + "\n\n")
+	}
+	for _, typ := range s.Types {
+		if strings.HasPrefix(typ.Name, "__") || preludeTypes[typ.Name] {
+			continue
+		}
+		writeDescription(builder, "", typ.Description)
+		switch typ.Kind {
+		case "SCALAR":
+			builder.WriteString("scalar " + typ.Name + "\n\n")
+
+		case "OBJECT", "INTERFACE":
+			keyword := "type"
+			if typ.Kind == "INTERFACE" {
+				keyword = "interface"
+			}
+			builder.WriteString(keyword + " " + typ.Name)
+			if len(typ.Interfaces) > 0 {
+				names := []string{}
+				for _, typeRef := range typ.Interfaces {
+					names = append(names, typeRef.Name)
+				}
+				builder.WriteString(" implements " + strings.Join(names, " & "))
+			}
+			builder.WriteString(" {\n")
+			for _, field := range typ.Fields {
+				writeDescription(builder, "  ", field.Description)
+				builder.WriteString("  " + field.Name + inputValuesSDL(field.Args) + ": " + field.Type.String())
+				builder.WriteString(deprecatedSDL(field.IsDeprecated, field.DeprecationReason) + "\n")
+			}
+			builder.WriteString("}\n\n")
+
+		case "UNION":
+			names := []string{}
+			for _, typeRef := range typ.PossibleTypes {
+				names = append(names, typeRef.Name)
+			}
+			builder.WriteString("union " + typ.Name + " = " + strings.Join(names, " | ") + "\n\n")
+
+		case "ENUM":
+			builder.WriteString("enum " + typ.Name + " {\n")
+			for _, value := range typ.EnumValues {
+				writeDescription(builder, "  ", value.Description)
+				builder.WriteString("  " + value.Name + deprecatedSDL(value.IsDeprecated, value.DeprecationReason) + "\n")
+			}
+			builder.WriteString("}\n\n")
+
+		case "INPUT_OBJECT":
+			builder.WriteString("input " + typ.Name + " {\n")
+			for _, field := range typ.InputFields {
+				writeDescription(builder, "  ", field.Description)
+				builder.WriteString("  " + inputValueSDL(field) + "\n")
+			}
+			builder.WriteString("}\n\n")
+		}
+	}
+	return builder.String()
+}
+
+func inputValuesSDL(values []introspectionInputValue) string {
+	if len(values) == 0 {
+		return ""
+	}
+	args := []string{}
+	for _, value := range values {
+		args = append(args, inputValueSDL(value))
+	}
+	return "(" + strings.Join(args, ", ") + ")"
+}
+
+func inputValueSDL(value introspectionInputValue) string {
+	sdl := value.Name + ": " + value.Type.String()
+	if value.DefaultValue != nil {
+		sdl += " = " + *value.DefaultValue
+	}
+	return sdl
+}
+
+func deprecatedSDL(isDeprecated bool, reason *string) string {
+	if !isDeprecated {
+		return ""
+	}
+	if reason == nil {
+		return " @deprecated"
+	}
+	reasonBytes, _ := json.Marshal(*reason)
+	return " @deprecated(reason: " + string(reasonBytes) + ")"
+}
+
+// writeDescription writes a description as a block string.
+func writeDescription(builder *strings.Builder, indent, description string) {
+	if strings.TrimSpace(description) == "" {
+		return
+	}
+	description = strings.ReplaceAll(description, `"""`, `\"""`)
+	builder.WriteString(indent + `"""` + "\n" + indent + strings.ReplaceAll(description, "\n", "\n"+indent) + "\n" + indent + `"""` + "\n")
+}
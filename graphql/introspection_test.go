@@ -0,0 +1,178 @@
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/vektah/gqlparser/ast"
+)
+
+func TestLoadGraphqlSchemaFromEndpoint(t *testing.T) {
+	result, err := os.ReadFile("testdata/introspection.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var header http.Header
+	var query string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header = r.Header.Clone()
+		body := struct {
+			Query string `json:"query"`
+		}{}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		query = body.Query
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(result)
+	}))
+	defer server.Close()
+
+	schema, err := LoadGraphqlSchemaFromEndpoint(context.Background(), server.URL, map[string]string{
+		"Authorization": "Bearer token",
+		"X-Tenant":      "acme",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if query != IntrospectionQuery {
+		t.Errorf("the introspection query was not sent, got %q", query)
+	}
+	if got := header.Get("Authorization"); got != "Bearer token" {
+		t.Errorf("got Authorization header %q, want %q", got, "Bearer token")
+	}
+	if got := header.Get("X-Tenant"); got != "acme" {
+		t.Errorf("got X-Tenant header %q, want %q", got, "acme")
+	}
+	if got := header.Get("Content-Type"); got != "application/json" {
+		t.Errorf("got Content-Type header %q, want %q", got, "application/json")
+	}
+
+	for _, name := range []string{"User", "Post"} {
+		if _, ok := schema.Objects[name]; !ok {
+			t.Errorf("object %s is missing", name)
+		}
+	}
+	user := schema.Objects["User"]
+	if user != nil {
+		if user.Description != "A registered user." {
+			t.Errorf("got User description %q", user.Description)
+		}
+		if len(user.Interfaces) != 1 || user.Interfaces[0] != "Node" {
+			t.Errorf("got User interfaces %v, want [Node]", user.Interfaces)
+		}
+		nickname := user.Fields.ForName("nickname")
+		if nickname == nil || nickname.Directives.ForName("deprecated") == nil {
+			t.Error("User.nickname is not deprecated")
+		}
+	}
+
+	status, ok := schema.Enums["Status"]
+	if !ok {
+		t.Fatal("enum Status is missing")
+	}
+	values := []string{}
+	for _, value := range status.EnumValues {
+		values = append(values, value.Name)
+	}
+	if len(values) != 2 || values[0] != "ACTIVE" || values[1] != "IN_PROGRESS" {
+		t.Errorf("got Status values %v, want [ACTIVE IN_PROGRESS]", values)
+	}
+
+	node, ok := schema.Interfaces["Node"]
+	if !ok {
+		t.Fatal("interface Node is missing")
+	}
+	if node.Fields.ForName("id") == nil {
+		t.Error("Node.id is missing")
+	}
+	possibleTypes := map[string]bool{}
+	for _, definition := range schema.AstSchema.GetPossibleTypes(node) {
+		possibleTypes[definition.Name] = true
+	}
+	if !possibleTypes["User"] || !possibleTypes["Post"] {
+		t.Errorf("got Node possible types %v, want User and Post", possibleTypes)
+	}
+
+	searchResult, ok := schema.Unions["SearchResult"]
+	if !ok {
+		t.Fatal("union SearchResult is missing")
+	}
+	if len(searchResult.Types) != 2 || searchResult.Types[0] != "User" || searchResult.Types[1] != "Post" {
+		t.Errorf("got SearchResult types %v, want [User Post]", searchResult.Types)
+	}
+
+	input, ok := schema.Inputs["UserInput"]
+	if !ok {
+		t.Fatal("input UserInput is missing")
+	}
+	if field := input.Fields.ForName("status"); field == nil || field.DefaultValue == nil || field.DefaultValue.Raw != "ACTIVE" {
+		t.Error("the default value of UserInput.status is not ACTIVE")
+	}
+
+	// the wrapping of the introspection type references is kept.
+	types := []struct {
+		definition *ast.Definition
+		field      string
+		want       string
+	}{
+		{user, "id", "ID!"},
+		{user, "name", "String"},
+		{user, "status", "Status!"},
+		{user, "tags", "[[String!]]!"},
+		{schema.AstSchema.Query, "search", "[SearchResult!]!"},
+		{schema.AstSchema.Query, "users", "[User]"},
+		{schema.AstSchema.Mutation, "createUser", "User!"},
+	}
+	for _, test := range types {
+		if test.definition == nil {
+			continue
+		}
+		field := test.definition.Fields.ForName(test.field)
+		if field == nil {
+			t.Errorf("%s.%s is missing", test.definition.Name, test.field)
+			continue
+		}
+		if got := field.Type.String(); got != test.want {
+			t.Errorf("got %s.%s type %s, want %s", test.definition.Name, test.field, got, test.want)
+		}
+	}
+	if len(schema.Queries) == 0 || len(schema.Mutations) != 1 {
+		t.Errorf("got %d queries and %d mutations", len(schema.Queries), len(schema.Mutations))
+	}
+	users := schema.AstSchema.Query.Fields.ForName("users")
+	if users == nil || users.Arguments.ForName("first") == nil || users.Arguments.ForName("first").DefaultValue.Raw != "10" {
+		t.Error("the default value of the users first argument is not 10")
+	}
+}
+
+func TestLoadGraphqlSchemaFromEndpointErrors(t *testing.T) {
+	tests := []struct {
+		name       string
+		statusCode int
+		body       string
+	}{
+		{"status code", http.StatusUnauthorized, `{"errors":[{"message":"unauthorized"}]}`},
+		{"graphql errors", http.StatusOK, `{"errors":[{"message":"introspection is disabled"}]}`},
+		{"no schema", http.StatusOK, `{"data":{}}`},
+		{"invalid json", http.StatusOK, `<html></html>`},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(test.statusCode)
+				w.Write([]byte(test.body))
+			}))
+			defer server.Close()
+			if _, err := LoadGraphqlSchemaFromEndpoint(context.Background(), server.URL, nil); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
@@ -0,0 +1,341 @@
+package graphql
+
+import (
+	"bytes"
+	"crypto/sha256"
+	_ "embed"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/iancoleman/strcase"
+	"github.com/vektah/gqlparser/ast"
+	"github.com/vektah/gqlparser/formatter"
+	"github.com/vektah/gqlparser/parser"
+	"github.com/vektah/gqlparser/validator"
+	"golang.org/x/tools/imports"
+)
+
+//go:embed templates/operations.go.tmpl
+var operationsTemplateFile string
+
+// Operation is a validated graphql operation document together with the
+// Go types of its variables and response, Hash is the hex encoded SHA-256
+// hash of the document used as the id of persisted queries.
+// BatchSelection and BatchFragments are used to merge the operation into
+// aliased batch documents, BatchSelection is empty if it can not be merged.
+type Operation struct {
+	Name           string
+	Definition     *ast.OperationDefinition
+	Document       string
+	Hash           string
+	Variables      []OperationVariable
+	ResponseType   string
+	Types          []OperationType
+	BatchSelection string
+	BatchFragments []string
+}
+
+// OperationVariable is a variable of an operation, Definition is its
+// graphql type and default value.
+type OperationVariable struct {
+	Name       string
+	ArgName    string
+	TypeName   string
+	Definition string
+	Required   bool
+}
+
+// OperationType is a Go struct generated for a selection set of an
+// operation.
+type OperationType struct {
+	Name   string
+	Fields []OperationField
+}
+
+// OperationField is a field of a generated operation struct.
+type OperationField struct {
+	Name     string
+	JSONName string
+	TypeName string
+}
+
+// selectedField is a response field of a selection set after fragments
+// have been merged into it.
+type selectedField struct {
+	responseName string
+	definition   *ast.FieldDefinition
+	optional     bool
+	selectionSet ast.SelectionSet
+}
+
+// LoadOperations parses the operation documents and validates them
+// against the schema.
+func LoadOperations(schema *Schema, filenames ...string) ([]*Operation, error) {
+	document := &ast.QueryDocument{}
+	for _, filename := range filenames {
+		fileContents, err := os.ReadFile(filename)
+		if err != nil {
+			return nil, err
+		}
+		queryDocument, gqlErr := parser.ParseQuery(&ast.Source{
+			Name:  filename,
+			Input: string(fileContents),
+		})
+		if gqlErr != nil {
+			return nil, gqlErr
+		}
+		document.Operations = append(document.Operations, queryDocument.Operations...)
+		document.Fragments = append(document.Fragments, queryDocument.Fragments...)
+	}
+	if errs := validator.Validate(schema.AstSchema, document); errs != nil {
+		return nil, errs
+	}
+	operations := []*Operation{}
+	for _, definition := range document.Operations {
+		operation, err := newOperation(schema, document, definition)
+		if err != nil {
+			return nil, err
+		}
+		operations = append(operations, operation)
+	}
+	sort.Slice(operations, func(i, j int) bool {
+		return operations[i].Name < operations[j].Name
+	})
+	return operations, nil
+}
+
+func newOperation(schema *Schema, document *ast.QueryDocument, definition *ast.OperationDefinition) (*Operation, error) {
+	position := ""
+	if definition.Position != nil && definition.Position.Src != nil {
+		position = fmt.Sprintf("%s:%d: ", definition.Position.Src.Name, definition.Position.Line)
+	}
+	if definition.Name == "" {
+		return nil, fmt.Errorf("%soperations must be named", position)
+	}
+	name := strcase.ToCamel(definition.Name)
+	if name == "Query" || name == "Mutation" || name == "Subscription" || name == "NewBatch" || name == "Send" {
+		return nil, fmt.Errorf("%soperation name %s is reserved", position, definition.Name)
+	}
+	operation := &Operation{
+		Name:         name,
+		Definition:   definition,
+		ResponseType: name + "Response",
+	}
+	buffer := &bytes.Buffer{}
+	formatter.NewFormatter(buffer).FormatQueryDocument(&ast.QueryDocument{
+		Operations: ast.OperationList{definition},
+		Fragments:  usedFragments(document, definition.SelectionSet, map[string]bool{}),
+	})
+	operation.Document = strings.TrimSpace(buffer.String())
+	hash := sha256.Sum256([]byte(operation.Document))
+	operation.Hash = hex.EncodeToString(hash[:])
+	operation.BatchSelection, operation.BatchFragments = batchSelection(document, definition)
+	for _, variable := range definition.VariableDefinitions {
+		variableDefinition := variable.Type.String()
+		if variable.DefaultValue != nil {
+			variableDefinition += " = " + variable.DefaultValue.String()
+		}
+		operation.Variables = append(operation.Variables, OperationVariable{
+			Name:       variable.Variable,
+			ArgName:    argumentName(variable.Variable),
+			TypeName:   operationTypeName(schema, variable.Type, "", false),
+			Definition: variableDefinition,
+			Required:   variable.Type.NonNull,
+		})
+	}
+	rootType := schema.AstSchema.Query
+	switch definition.Operation {
+	case ast.Mutation:
+		rootType = schema.AstSchema.Mutation
+	case ast.Subscription:
+		rootType = schema.AstSchema.Subscription
+	}
+	operation.addType(schema, operation.ResponseType, name, rootType.Name, definition.SelectionSet)
+	return operation, nil
+}
+
+// addType adds the struct of a selection set and of its nested selection
+// sets to the operation types.
+func (o *Operation) addType(schema *Schema, typeName, prefix, parentType string, selectionSet ast.SelectionSet) {
+	fields := []*selectedField{}
+	collectFields(parentType, selectionSet, false, &fields, map[string]*selectedField{})
+	operationType := OperationType{Name: typeName}
+	nested := []func(){}
+	for _, field := range fields {
+		fieldName := strcase.ToCamel(field.responseName)
+		structName := ""
+		if len(field.selectionSet) > 0 {
+			structName = prefix + fieldName
+			field := field
+			nested = append(nested, func() {
+				o.addType(schema, structName, structName, field.definition.Type.Name(), field.selectionSet)
+			})
+		}
+		typeName := "string"
+		if field.definition.Name != "__typename" {
+			typeName = operationTypeName(schema, field.definition.Type, structName, field.optional)
+		}
+		operationType.Fields = append(operationType.Fields, OperationField{
+			Name:     fieldName,
+			JSONName: field.responseName,
+			TypeName: typeName,
+		})
+	}
+	o.Types = append(o.Types, operationType)
+	for _, addNested := range nested {
+		addNested()
+	}
+}
+
+// collectFields merges the fields of a selection set and of its fragments
+// by response name, fields of fragments on other types than the parent
+// type and fields with @skip / @include directives are optional.
+func collectFields(parentType string, selectionSet ast.SelectionSet, optional bool, fields *[]*selectedField, index map[string]*selectedField) {
+	for _, selection := range selectionSet {
+		switch selection := selection.(type) {
+		case *ast.Field:
+			fieldOptional := optional || isConditional(selection.Directives)
+			if field, ok := index[selection.Alias]; ok {
+				field.optional = field.optional && fieldOptional
+				field.selectionSet = append(field.selectionSet, selection.SelectionSet...)
+				continue
+			}
+			field := &selectedField{
+				responseName: selection.Alias,
+				definition:   selection.Definition,
+				optional:     fieldOptional,
+				selectionSet: append(ast.SelectionSet{}, selection.SelectionSet...),
+			}
+			index[selection.Alias] = field
+			*fields = append(*fields, field)
+
+		case *ast.InlineFragment:
+			fragmentOptional := optional || isConditional(selection.Directives) ||
+				(selection.TypeCondition != "" && selection.TypeCondition != parentType)
+			collectFields(parentType, selection.SelectionSet, fragmentOptional, fields, index)
+
+		case *ast.FragmentSpread:
+			fragmentOptional := optional || isConditional(selection.Directives) ||
+				selection.Definition.TypeCondition != parentType
+			collectFields(parentType, selection.Definition.SelectionSet, fragmentOptional, fields, index)
+		}
+	}
+}
+
+func isConditional(directives ast.DirectiveList) bool {
+	return directives.ForName("skip") != nil || directives.ForName("include") != nil
+}
+
+// usedFragments returns the fragments spread in a selection set,
+// including the fragments spread by those fragments.
+func usedFragments(document *ast.QueryDocument, selectionSet ast.SelectionSet, seen map[string]bool) ast.FragmentDefinitionList {
+	fragments := ast.FragmentDefinitionList{}
+	for _, selection := range selectionSet {
+		switch selection := selection.(type) {
+		case *ast.Field:
+			fragments = append(fragments, usedFragments(document, selection.SelectionSet, seen)...)
+
+		case *ast.InlineFragment:
+			fragments = append(fragments, usedFragments(document, selection.SelectionSet, seen)...)
+
+		case *ast.FragmentSpread:
+			if seen[selection.Name] {
+				continue
+			}
+			seen[selection.Name] = true
+			fragment := document.Fragments.ForName(selection.Name)
+			fragments = append(fragments, fragment)
+			fragments = append(fragments, usedFragments(document, fragment.SelectionSet, seen)...)
+		}
+	}
+	return fragments
+}
+
+// operationTypeName returns the Go type of a graphql type, structName is
+// used for object, union and interface types.
+func operationTypeName(schema *Schema, typ *ast.Type, structName string, optional bool) string {
+	if typ.Elem != nil {
+		return "[]" + operationTypeName(schema, typ.Elem, structName, false)
+	}
+	typeName := structName
+	if typeName == "" {
+		typeName = namedTypeName(schema, typ.NamedType)
+	}
+	if typeName == "interface{}" || (typ.NonNull && !optional) {
+		return typeName
+	}
+	return "*" + typeName
+}
+
+// namedTypeName returns the Go type of a graphql scalar, enum or input.
+func namedTypeName(schema *Schema, name string) string {
+	if typeName, ok := schema.scalarTypeName(name); ok {
+		return typeName
+	}
+	if _, ok := schema.Scalars[name]; ok {
+		return "interface{}"
+	}
+	return goTypeName(name)
+}
+
+// GenerateOperations generates a Go function for every graphql operation
+// in the operation files, the functions are added to the client generated
+// by GenerateGoSDK in the same output directory.
+func GenerateOperations(schemaFile string, operationFiles []string, outputDirectory string, scalars ...ScalarMapping) error {
+	schema, err := LoadGraphqlSchema(schemaFile)
+	if err != nil {
+		return err
+	}
+	err = schema.MapScalars(scalars...)
+	if err != nil {
+		return err
+	}
+	return GenerateOperationsClient(schema, operationFiles, outputDirectory)
+}
+
+// GenerateOperationsClient generates a Go function for every graphql
+// operation in the operation files using a loaded schema.
+func GenerateOperationsClient(schema *Schema, operationFiles []string, outputDirectory string) error {
+	operations, err := LoadOperations(schema, operationFiles...)
+	if err != nil {
+		return err
+	}
+	err = os.MkdirAll(outputDirectory, 0700)
+	if err != nil {
+		return err
+	}
+	outFile := outputDirectory + "/operations.go"
+	operationsTmp, err := template.New("operations.go.tmpl").Funcs(template.FuncMap{
+		"goString": goString,
+	}).Parse(operationsTemplateFile)
+	if err != nil {
+		return err
+	}
+	buffer := &bytes.Buffer{}
+	err = operationsTmp.Execute(buffer, struct {
+		Schema     *Schema
+		Operations []*Operation
+	}{schema, operations})
+	if err != nil {
+		return err
+	}
+	res, err := imports.Process(outFile, buffer.Bytes(), nil)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(outFile, res, 0700)
+}
+
+// goString returns a Go string literal, raw strings are used unless the
+// string contains a backtick.
+func goString(str string) string {
+	if strings.Contains(str, "`") {
+		return strconv.Quote(str)
+	}
+	return "`" + str + "`"
+}
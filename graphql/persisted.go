@@ -0,0 +1,62 @@
+package graphql
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+)
+
+// PersistedQueryManifest is a manifest of the operation documents in the
+// apollo-persisted-query-manifest format, it is used by servers to allow
+// list persisted queries.
+type PersistedQueryManifest struct {
+	Format     string                    `json:"format"`
+	Version    int                       `json:"version"`
+	Operations []PersistedQueryOperation `json:"operations"`
+}
+
+// PersistedQueryOperation is an operation of a persisted query manifest,
+// the id is the SHA-256 hash of the body.
+type PersistedQueryOperation struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	Type string `json:"type"`
+	Body string `json:"body"`
+}
+
+// NewPersistedQueryManifest returns the persisted query manifest of the
+// operations.
+func NewPersistedQueryManifest(operations []*Operation) *PersistedQueryManifest {
+	manifest := &PersistedQueryManifest{
+		Format:     "apollo-persisted-query-manifest",
+		Version:    1,
+		Operations: []PersistedQueryOperation{},
+	}
+	for _, operation := range operations {
+		manifest.Operations = append(manifest.Operations, PersistedQueryOperation{
+			ID:   operation.Hash,
+			Name: operation.Definition.Name,
+			Type: string(operation.Definition.Operation),
+			Body: operation.Document,
+		})
+	}
+	return manifest
+}
+
+// GeneratePersistedQueryManifest writes the persisted query manifest of
+// the operations in the operation files to manifestFile.
+func GeneratePersistedQueryManifest(schema *Schema, operationFiles []string, manifestFile string) error {
+	operations, err := LoadOperations(schema, operationFiles...)
+	if err != nil {
+		return err
+	}
+	contents, err := json.MarshalIndent(NewPersistedQueryManifest(operations), "", "  ")
+	if err != nil {
+		return err
+	}
+	err = os.MkdirAll(filepath.Dir(manifestFile), 0700)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(manifestFile, append(contents, '\n'), 0700)
+}
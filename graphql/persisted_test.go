@@ -0,0 +1,65 @@
+package graphql
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGeneratePersistedQueryManifest(t *testing.T) {
+	schema, err := LoadGraphqlSchema("testdata/persisted.graphql")
+	if err != nil {
+		t.Fatal(err)
+	}
+	manifestFile := filepath.Join(t.TempDir(), "queries", "manifest.json")
+	if err := GeneratePersistedQueryManifest(schema, []string{"testdata/persisted_operations.graphql"}, manifestFile); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(manifestFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var manifest PersistedQueryManifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		t.Fatal(err)
+	}
+	if manifest.Format != "apollo-persisted-query-manifest" || manifest.Version != 1 {
+		t.Errorf("got manifest format %s version %d", manifest.Format, manifest.Version)
+	}
+	want := map[string]string{"GetPost": "query", "LikePost": "mutation"}
+	if len(manifest.Operations) != len(want) {
+		t.Fatalf("got operations %+v", manifest.Operations)
+	}
+	for _, operation := range manifest.Operations {
+		if want[operation.Name] != operation.Type {
+			t.Errorf("got %s operation type %q, want %q", operation.Name, operation.Type, want[operation.Name])
+		}
+		hash := sha256.Sum256([]byte(operation.Body))
+		if operation.ID != hex.EncodeToString(hash[:]) {
+			t.Errorf("got %s id %s, want the SHA-256 hash of the body", operation.Name, operation.ID)
+		}
+	}
+	if body := manifest.Operations[0].Body; !strings.Contains(body, "fragment PostFields on Post") {
+		t.Errorf("got GetPost body %q, want the used fragments to be included", body)
+	}
+}
+
+func TestGeneratedPersistedQueries(t *testing.T) {
+	operationFiles := []string{"testdata/persisted_operations.graphql"}
+	dir := generateOperationsTestClient(t, "testdata/persisted.graphql", operationFiles, map[string]string{
+		"client_test.go": "testdata/persisted_client_test.go",
+	})
+	schema, err := LoadGraphqlSchema("testdata/persisted.graphql")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := GeneratePersistedQueryManifest(schema, operationFiles, filepath.Join(dir, "testdata", "manifest.json")); err != nil {
+		t.Fatal(err)
+	}
+	runGo(t, dir, "vet", ".")
+	runGo(t, dir, "test", "-count=1", ".")
+}
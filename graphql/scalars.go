@@ -0,0 +1,200 @@
+package graphql
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/vektah/gqlparser/ast"
+)
+
+// uploadScalar is the scalar of the graphql multipart request spec, its
+// values are sent as the files of multipart requests.
+const uploadScalar = "Upload"
+
+var (
+	majorVersionRegexp  = regexp.MustCompile(`^v[0-9]+$`)
+	nonIdentifierRegexp = regexp.MustCompile(`[^A-Za-z0-9_]`)
+)
+
+// ScalarMapping maps a graphql scalar to a Go type. Type, Marshal and
+// Unmarshal can be qualified by their import path, e.g.
+// github.com/google/uuid.UUID, or the import path can be set in Import.
+// Marshal must be a func(T) ([]byte, error) and Unmarshal a
+// func([]byte) (T, error), values are then wrapped in a generated type
+// named after the scalar.
+type ScalarMapping struct {
+	Scalar    string `mapstructure:"scalar"`
+	Type      string `mapstructure:"type"`
+	Import    string `mapstructure:"import"`
+	Marshal   string `mapstructure:"marshal"`
+	Unmarshal string `mapstructure:"unmarshal"`
+}
+
+// ParseScalarMapping parses a SCALAR=TYPE scalar mapping.
+func ParseScalarMapping(str string) (ScalarMapping, error) {
+	parts := strings.SplitN(str, "=", 2)
+	if len(parts) != 2 || strings.TrimSpace(parts[0]) == "" || strings.TrimSpace(parts[1]) == "" {
+		return ScalarMapping{}, fmt.Errorf("invalid scalar mapping %s, expected SCALAR=TYPE", str)
+	}
+	return ScalarMapping{
+		Scalar: strings.TrimSpace(parts[0]),
+		Type:   strings.TrimSpace(parts[1]),
+	}, nil
+}
+
+// HasMarshaler returns true if the scalar is encoded with custom
+// functions.
+func (m ScalarMapping) HasMarshaler() bool {
+	return m.Marshal != "" || m.Unmarshal != ""
+}
+
+// MapScalars sets the Go types of the schema scalars, Type, Marshal and
+// Unmarshal of the stored mappings are set to the Go expressions used in
+// the generated code.
+func (s *Schema) MapScalars(mappings ...ScalarMapping) error {
+	for _, mapping := range mappings {
+		if mapping.Scalar == "" || mapping.Type == "" {
+			return fmt.Errorf("scalar mapping %q requires a scalar name and type", mapping.Scalar)
+		}
+		var err error
+		mapping.Type, err = s.qualify(mapping.Type, mapping.Import)
+		if err != nil {
+			return fmt.Errorf("scalar %s: %w", mapping.Scalar, err)
+		}
+		if mapping.HasMarshaler() && (mapping.Marshal == "" || mapping.Unmarshal == "") {
+			return fmt.Errorf("scalar %s: marshal and unmarshal functions must be set together", mapping.Scalar)
+		}
+		if mapping.HasMarshaler() {
+			mapping.Marshal, err = s.qualify(mapping.Marshal, "")
+			if err != nil {
+				return fmt.Errorf("scalar %s: %w", mapping.Scalar, err)
+			}
+			mapping.Unmarshal, err = s.qualify(mapping.Unmarshal, "")
+			if err != nil {
+				return fmt.Errorf("scalar %s: %w", mapping.Scalar, err)
+			}
+		}
+		s.ScalarMappings[s.scalarName(mapping.Scalar)] = mapping
+	}
+	return nil
+}
+
+// scalarName returns the schema name of a scalar matched case
+// insensitively, config file keys are lower cased.
+func (s *Schema) scalarName(name string) string {
+	if _, ok := s.AstSchema.Types[name]; ok {
+		return name
+	}
+	for typeName, definition := range s.AstSchema.Types {
+		if definition.Kind == ast.Scalar && strings.EqualFold(typeName, name) {
+			return typeName
+		}
+	}
+	return name
+}
+
+// qualify returns the Go expression of a name qualified by its import
+// path and adds the import path to the schema imports.
+func (s *Schema) qualify(name, importPath string) (string, error) {
+	prefix := ""
+	for strings.HasPrefix(name, "*") || strings.HasPrefix(name, "[]") {
+		if strings.HasPrefix(name, "*") {
+			prefix += "*"
+			name = name[1:]
+			continue
+		}
+		prefix += "[]"
+		name = name[2:]
+	}
+	dot := strings.LastIndex(name, ".")
+	if importPath == "" {
+		if dot < 0 || strings.LastIndex(name, "/") < 0 || dot < strings.LastIndex(name, "/") {
+			return prefix + name, nil
+		}
+		importPath, name = name[:dot], name[dot+1:]
+	} else if dot >= 0 {
+		name = name[dot+1:]
+	}
+	alias := packageAlias(importPath)
+	if alias == "" {
+		return "", fmt.Errorf("invalid import path %s", importPath)
+	}
+	if existing, ok := s.Imports[importPath]; ok {
+		alias = existing
+	}
+	s.Imports[importPath] = alias
+	return prefix + alias + "." + name, nil
+}
+
+// packageAlias returns the import alias of an import path, major version
+// suffixes are skipped.
+func packageAlias(importPath string) string {
+	elements := strings.Split(strings.Trim(importPath, "/"), "/")
+	name := elements[len(elements)-1]
+	if majorVersionRegexp.MatchString(name) && len(elements) > 1 {
+		name = elements[len(elements)-2]
+	}
+	if index := strings.Index(name, ".v"); index > 0 {
+		name = name[:index]
+	}
+	name = nonIdentifierRegexp.ReplaceAllString(strings.TrimPrefix(name, "go-"), "")
+	if name != "" && name[0] >= '0' && name[0] <= '9' {
+		name = "pkg" + name
+	}
+	return name
+}
+
+// scalarTypeName returns the Go type of a mapped or default scalar.
+func (s *Schema) scalarTypeName(name string) (string, bool) {
+	if mapping, ok := s.ScalarMappings[name]; ok {
+		if mapping.HasMarshaler() {
+			return goTypeName(name), true
+		}
+		return mapping.Type, true
+	}
+	if name == uploadScalar && s.HasUploads() {
+		return "Upload", true
+	}
+	typeName, ok := graphqlDefaultFieldsMap[name]
+	return typeName, ok
+}
+
+// UnmappedScalars returns the custom scalars of the schema that are not
+// mapped to a Go type.
+func (s *Schema) UnmappedScalars() []string {
+	scalars := []string{}
+	for name := range s.Scalars {
+		if name == uploadScalar && s.HasUploads() {
+			continue
+		}
+		if _, ok := s.ScalarMappings[name]; !ok && !strings.HasPrefix(name, "_") {
+			scalars = append(scalars, name)
+		}
+	}
+	sort.Strings(scalars)
+	return scalars
+}
+
+// MarshaledScalars returns the scalar mappings that use custom marshal
+// functions.
+func (s *Schema) MarshaledScalars() map[string]ScalarMapping {
+	mappings := make(map[string]ScalarMapping)
+	for name, mapping := range s.ScalarMappings {
+		if mapping.HasMarshaler() {
+			mappings[name] = mapping
+		}
+	}
+	return mappings
+}
+
+// HasUploads returns true if the schema defines the Upload scalar of the
+// graphql multipart request spec and it is not mapped to a Go type.
+func (s *Schema) HasUploads() bool {
+	if _, ok := s.ScalarMappings[uploadScalar]; ok {
+		return false
+	}
+	definition, ok := s.AstSchema.Types[uploadScalar]
+	return ok && definition.Kind == ast.Scalar
+}
@@ -0,0 +1,164 @@
+package graphql
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/vektah/gqlparser"
+	"github.com/vektah/gqlparser/ast"
+)
+
+func TestParseScalarMapping(t *testing.T) {
+	mapping, err := ParseScalarMapping(" UUID = github.com/google/uuid.UUID ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mapping != (ScalarMapping{Scalar: "UUID", Type: "github.com/google/uuid.UUID"}) {
+		t.Errorf("got mapping %+v", mapping)
+	}
+	for _, invalid := range []string{"UUID", "UUID=", "=string", ""} {
+		if _, err := ParseScalarMapping(invalid); err == nil {
+			t.Errorf("the invalid mapping %q was parsed", invalid)
+		}
+	}
+}
+
+func TestPackageAlias(t *testing.T) {
+	tests := map[string]string{
+		"github.com/google/uuid":             "uuid",
+		"encoding/json":                      "json",
+		"github.com/jackc/pgx/v5":            "pgx",
+		"gopkg.in/yaml.v2":                   "yaml",
+		"github.com/mattn/go-sqlite3":        "sqlite3",
+		"github.com/acme/2fa":                "pkg2fa",
+		"github.com/shopspring/decimal-ext/": "decimalext",
+	}
+	for importPath, want := range tests {
+		if got := packageAlias(importPath); got != want {
+			t.Errorf("packageAlias(%q) = %q, want %q", importPath, got, want)
+		}
+	}
+}
+
+func TestMapScalars(t *testing.T) {
+	astSchema, err := gqlparser.LoadSchema(&ast.Source{Input: `
+scalar UUID
+scalar BigInt
+scalar Decimal
+scalar Any
+
+type Query {
+  id: UUID
+}
+`})
+	if err != nil {
+		t.Fatal(err)
+	}
+	schema := parseSchema(NewSchema(astSchema))
+	if err := schema.MapScalars(
+		ScalarMapping{Scalar: "uuid", Type: "github.com/google/uuid.UUID"},
+		ScalarMapping{Scalar: "BigInt", Type: "*math/big.Int"},
+		ScalarMapping{Scalar: "Decimal", Type: "Decimal", Import: "github.com/shopspring/decimal", Marshal: "github.com/acme/scalars.MarshalDecimal", Unmarshal: "github.com/acme/scalars.UnmarshalDecimal"},
+	); err != nil {
+		t.Fatal(err)
+	}
+	for name, want := range map[string]string{"UUID": "uuid.UUID", "BigInt": "*big.Int", "Decimal": "Decimal", "String": "string"} {
+		if got, ok := schema.scalarTypeName(name); !ok || got != want {
+			t.Errorf("got %s type %q, want %q", name, got, want)
+		}
+	}
+	decimal := schema.ScalarMappings["Decimal"]
+	if decimal.Type != "decimal.Decimal" || decimal.Marshal != "scalars.MarshalDecimal" || decimal.Unmarshal != "scalars.UnmarshalDecimal" {
+		t.Errorf("got decimal mapping %+v", decimal)
+	}
+	wantImports := map[string]string{
+		"github.com/google/uuid":        "uuid",
+		"math/big":                      "big",
+		"github.com/shopspring/decimal": "decimal",
+		"github.com/acme/scalars":       "scalars",
+	}
+	if len(schema.Imports) != len(wantImports) {
+		t.Errorf("got imports %v, want %v", schema.Imports, wantImports)
+	}
+	for path, alias := range wantImports {
+		if schema.Imports[path] != alias {
+			t.Errorf("got import %s alias %q, want %q", path, schema.Imports[path], alias)
+		}
+	}
+	if unmapped := schema.UnmappedScalars(); len(unmapped) != 1 || unmapped[0] != "Any" {
+		t.Errorf("got unmapped scalars %v, want [Any]", unmapped)
+	}
+
+	for _, invalid := range []ScalarMapping{
+		{Scalar: "UUID"},
+		{Type: "string"},
+		{Scalar: "Decimal", Type: "string", Marshal: "encoding/json.Marshal"},
+	} {
+		if err := schema.MapScalars(invalid); err == nil {
+			t.Errorf("the invalid mapping %+v was accepted", invalid)
+		}
+	}
+}
+
+func TestGeneratedScalars(t *testing.T) {
+	stdout := os.Stdout
+	reader, writer, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	os.Stdout = writer
+	dir := generateTestClient(t, "testdata/scalars.graphql", map[string]string{
+		"client_test.go":     "testdata/scalars_client_test.go",
+		"scalars/scalars.go": "testdata/scalars/scalars.go",
+	},
+		ScalarMapping{Scalar: "uuid", Type: "string"},
+		ScalarMapping{Scalar: "BigInt", Type: "int64"},
+		ScalarMapping{Scalar: "JSON", Type: "encoding/json.RawMessage"},
+		ScalarMapping{Scalar: "Timestamp", Type: "time.Time", Marshal: "client/scalars.MarshalTimestamp", Unmarshal: "client/scalars.UnmarshalTimestamp"},
+	)
+	os.Stdout = stdout
+	writer.Close()
+	output := &bytes.Buffer{}
+	io.Copy(output, reader)
+	if !strings.Contains(output.String(), "scalars without a Go type mapping are generated as interface{}: Any\n") {
+		t.Errorf("got output %q, want the unmapped scalars warning", output)
+	}
+	runGo(t, dir, "vet", ".")
+	runGo(t, dir, "test", "-count=1", ".")
+}
+
+func TestHasUploads(t *testing.T) {
+	astSchema, err := gqlparser.LoadSchema(&ast.Source{Input: `
+scalar Upload
+
+type Query {
+  file: Upload
+}
+`})
+	if err != nil {
+		t.Fatal(err)
+	}
+	schema := parseSchema(NewSchema(astSchema))
+	if !schema.HasUploads() {
+		t.Error("the Upload scalar was not detected")
+	}
+	if typeName, ok := schema.scalarTypeName("Upload"); !ok || typeName != "Upload" {
+		t.Errorf("got Upload type %q", typeName)
+	}
+	if unmapped := schema.UnmappedScalars(); len(unmapped) != 0 {
+		t.Errorf("got unmapped scalars %v", unmapped)
+	}
+	if err := schema.MapScalars(ScalarMapping{Scalar: "Upload", Type: "string"}); err != nil {
+		t.Fatal(err)
+	}
+	if schema.HasUploads() {
+		t.Error("the mapped Upload scalar is generated as an upload")
+	}
+}
+
+func TestGeneratedUploads(t *testing.T) {
+	testClient(t, "testdata/uploads.graphql", "testdata/uploads_client_test.go")
+}
@@ -0,0 +1,90 @@
+package graphql
+
+import (
+	"sort"
+
+	"github.com/iancoleman/strcase"
+	"github.com/vektah/gqlparser/ast"
+)
+
+var (
+	// reservedSelectorNames contains the methods shared by every generated
+	// selection type that field selectors must not collide with.
+	reservedSelectorNames = map[string]bool{
+		"Alias": true, "Typename": true, "String": true,
+	}
+
+	// reservedArgumentNames contains Go keywords and identifiers used by
+	// the generated query, mutation, subscription, selector, batch and
+	// operation methods that argument names must not shadow.
+	reservedArgumentNames = map[string]bool{
+		"break": true, "case": true, "chan": true, "const": true, "continue": true,
+		"default": true, "defer": true, "else": true, "fallthrough": true, "for": true,
+		"func": true, "go": true, "goto": true, "if": true, "import": true,
+		"interface": true, "map": true, "package": true, "range": true, "return": true,
+		"select": true, "struct": true, "switch": true, "type": true, "var": true,
+		"s": true, "fields": true, "dest": true, "b": true,
+		"ctx": true, "query": true, "variables": true, "err": true, "q": true, "m": true,
+		"gqlFields": true, "payloads": true, "results": true, "selection": true, "it": true,
+		"context": true, "fmt": true, "json": true, "c": true, "response": true,
+	}
+)
+
+// selectionTypeName returns the name of the generated selection type of a
+// field type, an empty string is returned for leaf (scalar and enum) types.
+func selectionTypeName(schema *Schema, typ *ast.Type) string {
+	definition, ok := schema.AstSchema.Types[typ.Name()]
+	if !ok {
+		return ""
+	}
+	switch definition.Kind {
+	case ast.Object, ast.Union, ast.Interface:
+		return goTypeName(definition.Name) + "Selection"
+	}
+	return ""
+}
+
+// selectorName returns the name of the selection method of a field.
+func selectorName(fieldName string) string {
+	name := strcase.ToCamel(fieldName)
+	if reservedSelectorNames[name] {
+		return name + "Field"
+	}
+	return name
+}
+
+// argumentName returns the Go parameter name of a field argument.
+func argumentName(name string) string {
+	name = strcase.ToLowerCamel(name)
+	if reservedArgumentNames[name] {
+		return name + "Arg"
+	}
+	return name
+}
+
+// possibleTypes returns the exported object types that can be returned
+// for a union or interface, sorted by name.
+func possibleTypes(schema *Schema, definition *ast.Definition) []*ast.Definition {
+	types := []*ast.Definition{}
+	for _, typ := range schema.AstSchema.GetPossibleTypes(definition) {
+		if _, ok := schema.Objects[typ.Name]; ok {
+			types = append(types, typ)
+		}
+	}
+	sort.Slice(types, func(i, j int) bool {
+		return types[i].Name < types[j].Name
+	})
+	return types
+}
+
+// AbstractTypes returns the union and interface types of the schema.
+func (s *Schema) AbstractTypes() map[string]*ast.Definition {
+	types := make(map[string]*ast.Definition)
+	for name, definition := range s.Unions {
+		types[name] = definition
+	}
+	for name, definition := range s.Interfaces {
+		types[name] = definition
+	}
+	return types
+}
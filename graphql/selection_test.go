@@ -0,0 +1,52 @@
+package graphql
+
+import "testing"
+
+func TestArgumentName(t *testing.T) {
+	tests := map[string]string{
+		"first":      "first",
+		"order_by":   "orderBy",
+		"type":       "typeArg",
+		"fields":     "fieldsArg",
+		"s":          "sArg",
+		"ctx":        "ctxArg",
+		"query":      "queryArg",
+		"variables":  "variablesArg",
+		"err":        "errArg",
+		"q":          "qArg",
+		"m":          "mArg",
+		"c":          "cArg",
+		"response":   "responseArg",
+		"PostFilter": "postFilter",
+	}
+	for name, want := range tests {
+		if got := argumentName(name); got != want {
+			t.Errorf("argumentName(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
+
+func TestSelectorName(t *testing.T) {
+	tests := map[string]string{
+		"name":     "Name",
+		"alias":    "AliasField",
+		"string":   "StringField",
+		"typename": "TypenameField",
+	}
+	for name, want := range tests {
+		if got := selectorName(name); got != want {
+			t.Errorf("selectorName(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
+
+func TestGeneratedSelection(t *testing.T) {
+	testClient(t, "testdata/selection.graphql", "testdata/selection_client_test.go")
+}
+
+// TestGenerateClientArguments generates a client whose operation
+// arguments are named like the locals of the generated methods.
+func TestGenerateClientArguments(t *testing.T) {
+	dir := generateTestClient(t, "testdata/arguments.graphql", nil)
+	runGo(t, dir, "vet", ".")
+}
@@ -0,0 +1,239 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+)
+
+var rootFieldRegexp = regexp.MustCompile(`(\w+): (\w+)\(id: \$(\w+)|(\w+)\(id: \$(\w+)`)
+
+type batchServerRequest struct {
+	Query     string                 `json:"query"`
+	Variables map[string]interface{} `json:"variables"`
+}
+
+// resolve resolves the root fields of a request, the fields with the id
+// "missing" are resolved to null with an error.
+func resolve(request batchServerRequest) map[string]interface{} {
+	data := map[string]interface{}{}
+	errs := []map[string]interface{}{}
+	for _, match := range rootFieldRegexp.FindAllStringSubmatch(request.Query, -1) {
+		key, field, variable := match[1], match[2], match[3]
+		if key == "" {
+			key, field, variable = match[4], match[4], match[5]
+		}
+		id, _ := request.Variables[variable].(string)
+		if id == "missing" {
+			data[key] = nil
+			errs = append(errs, map[string]interface{}{"message": field + " not found", "path": []string{key}})
+			continue
+		}
+		switch field {
+		case "user":
+			data[key] = map[string]interface{}{"id": id, "name": "user " + id}
+		case "post":
+			data[key] = map[string]interface{}{"id": id, "title": "post " + id, "likes": 1}
+		case "likePost":
+			data[key] = map[string]interface{}{"id": id, "title": "post " + id, "likes": 2}
+		}
+	}
+	response := map[string]interface{}{"data": data}
+	if len(errs) > 0 {
+		response["errors"] = errs
+	}
+	return response
+}
+
+type batchServer struct {
+	mu     sync.Mutex
+	bodies []string
+}
+
+func (s *batchServer) requests() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string{}, s.bodies...)
+}
+
+func newBatchServer(t *testing.T) (*GqlClient, *batchServer) {
+	batchServer := &batchServer{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		batchServer.mu.Lock()
+		batchServer.bodies = append(batchServer.bodies, r.URL.Path+" "+string(body))
+		batchServer.mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		if bytes.HasPrefix(body, []byte("[")) {
+			var requests []batchServerRequest
+			json.Unmarshal(body, &requests)
+			responses := []map[string]interface{}{}
+			for _, request := range requests {
+				responses = append(responses, resolve(request))
+			}
+			json.NewEncoder(w).Encode(responses)
+			return
+		}
+		var request batchServerRequest
+		json.Unmarshal(body, &request)
+		json.NewEncoder(w).Encode(resolve(request))
+	}))
+	t.Cleanup(server.Close)
+	return NewClient(ClientConfig{QueryURL: server.URL + "/query", MutationURL: server.URL + "/mutation"}), batchServer
+}
+
+func TestBatchArray(t *testing.T) {
+	client, server := newBatchServer(t)
+	batch := client.NewBatch()
+	var user, missing *User
+	var post GetPostResponse
+	var liked *Post
+	userResult := batch.Query.SelectUser(&user, "1", UserFields().Name())
+	postResult := batch.GetPost(&post, "2", nil)
+	missingResult := batch.Query.User(&missing, "missing", "{ name }")
+	likeResult := batch.Mutation.SelectLikePost(&liked, "3", PostFields().Id().Likes())
+	if err := batch.Send(context.Background(), BatchArray); err != nil {
+		t.Fatal(err)
+	}
+
+	if userResult.Err != nil || user == nil || user.Name != "user 1" {
+		t.Errorf("got user %+v, %v", user, userResult.Err)
+	}
+	if postResult.Err != nil || post.Post == nil || post.Post.Title == nil || *post.Post.Title != "post 2" || post.Post.Likes != 1 {
+		t.Errorf("got post %+v, %v", post.Post, postResult.Err)
+	}
+	if missingResult.Err == nil || missingResult.Err.Error() != "user: user not found" || missing != nil {
+		t.Errorf("got missing user %+v, %v", missing, missingResult.Err)
+	}
+	if likeResult.Err != nil || liked == nil || liked.Likes != 2 {
+		t.Errorf("got liked post %+v, %v", liked, likeResult.Err)
+	}
+
+	requests := server.requests()
+	if len(requests) != 2 {
+		t.Fatalf("got requests %q, want a query and a mutation batch", requests)
+	}
+	var queries []batchServerRequest
+	if !strings.HasPrefix(requests[0], "/query [") || json.Unmarshal([]byte(strings.TrimPrefix(requests[0], "/query ")), &queries) != nil || len(queries) != 3 {
+		t.Fatalf("got query batch %s", requests[0])
+	}
+	if queries[1].Query != GetPostDocument || len(queries[1].Variables) != 1 || queries[1].Variables["id"] != "2" {
+		t.Errorf("got GetPost request %+v", queries[1])
+	}
+	if want := "query($id: ID!) {\nuser: user(id: $id, ) { name }\n}"; queries[0].Query != want {
+		t.Errorf("got user query %q, want %q", queries[0].Query, want)
+	}
+	if !strings.HasPrefix(requests[1], "/mutation [") {
+		t.Errorf("got mutation batch %s", requests[1])
+	}
+}
+
+func TestBatchAliased(t *testing.T) {
+	client, server := newBatchServer(t)
+	batch := client.NewBatch()
+	var user, missing *User
+	var post GetPostResponse
+	var root GetPostRootResponse
+	var liked *Post
+	withTitle := false
+	userResult := batch.Query.SelectUser(&user, "1", UserFields().Name())
+	postResult := batch.GetPost(&post, "2", &withTitle)
+	rootResult := batch.GetPostRoot(&root, "2")
+	missingResult := batch.Query.User(&missing, "missing", "{ name }")
+	likeResult := batch.Mutation.SelectLikePost(&liked, "3", PostFields().Id().Likes())
+	if err := batch.Send(context.Background(), BatchAliased); err != nil {
+		t.Fatal(err)
+	}
+
+	if userResult.Err != nil || user == nil || user.Name != "user 1" {
+		t.Errorf("got user %+v, %v", user, userResult.Err)
+	}
+	if postResult.Err != nil || post.Post == nil || post.Post.Id != "2" {
+		t.Errorf("got post %+v, %v", post.Post, postResult.Err)
+	}
+	if rootResult.Err == nil || rootResult.Err.Error() != "the operation can not be merged into an aliased batch" {
+		t.Errorf("got root result error %v, want the merge error", rootResult.Err)
+	}
+	if missingResult.Err == nil || missingResult.Err.Error() != "user: user not found" || missing != nil {
+		t.Errorf("got missing user %+v, %v, want the error with the path of the request", missing, missingResult.Err)
+	}
+	if likeResult.Err != nil || liked == nil || liked.Likes != 2 {
+		t.Errorf("got liked post %+v, %v", liked, likeResult.Err)
+	}
+
+	requests := server.requests()
+	if len(requests) != 2 {
+		t.Fatalf("got requests %q, want a query and a mutation document", requests)
+	}
+	var query batchServerRequest
+	if !strings.HasPrefix(requests[0], "/query {") || json.Unmarshal([]byte(strings.TrimPrefix(requests[0], "/query ")), &query) != nil {
+		t.Fatalf("got query document %s", requests[0])
+	}
+	want := "query($b0_id: ID!, $b1_id: ID!, $b1_withTitle: Boolean = true, $b3_id: ID!) {\n" +
+		"b0_user: user(id: $b0_id, ) { name }\n" +
+		"b1_post: post(id: $b1_id) {\n\t\t... PostFields\n\t\ttitle @include(if: $b1_withTitle)\n\t}\n" +
+		"b3_user: user(id: $b3_id, ) { name }\n" +
+		"}\nfragment PostFields on Post {\n\tid\n\tlikes\n}"
+	if query.Query != want {
+		t.Errorf("got document\n%s\nwant\n%s", query.Query, want)
+	}
+	wantVariables := map[string]interface{}{"b0_id": "1", "b1_id": "2", "b1_withTitle": false, "b3_id": "missing"}
+	if len(query.Variables) != len(wantVariables) {
+		t.Errorf("got variables %v, want %v", query.Variables, wantVariables)
+	}
+	for name, value := range wantVariables {
+		if query.Variables[name] != value {
+			t.Errorf("got variable %s %v, want %v", name, query.Variables[name], value)
+		}
+	}
+	if !strings.HasPrefix(requests[1], `/mutation {"query":"mutation($b0_id: ID!)`) {
+		t.Errorf("got mutation document %s", requests[1])
+	}
+}
+
+func TestBatchSendError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/short" {
+			w.Write([]byte(`[{"data": {"user": null}}]`))
+			return
+		}
+		http.Error(w, "unavailable", http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	tests := map[string]struct {
+		url  string
+		mode BatchMode
+		want string
+	}{
+		"array status":   {url: server.URL, mode: BatchArray, want: "server returned a non-200 status code: 503"},
+		"aliased status": {url: server.URL, mode: BatchAliased, want: "server returned a non-200 status code: 503"},
+		"array results":  {url: server.URL + "/short", mode: BatchArray, want: "batch response has 1 results, expected 2"},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			batch := NewClient(ClientConfig{QueryURL: test.url}).NewBatch()
+			var first, second *User
+			results := []*BatchResult{
+				batch.Query.User(&first, "1", "{ name }"),
+				batch.Query.User(&second, "2", "{ name }"),
+			}
+			err := batch.Send(context.Background(), test.mode)
+			if err == nil || err.Error() != test.want {
+				t.Fatalf("got error %v, want %q", err, test.want)
+			}
+			for i, result := range results {
+				if result.Err != err {
+					t.Errorf("got result %d error %v, want the send error", i, result.Err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,210 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+)
+
+type connectionRequest struct {
+	Query     string                 `json:"query"`
+	Variables map[string]interface{} `json:"variables"`
+}
+
+// connectionServer serves the pages of the connections, the cursors are
+// the offsets of the next pages and the response of a request is written
+// by respond.
+type connectionServer struct {
+	mu       sync.Mutex
+	requests []connectionRequest
+	respond  func(w http.ResponseWriter, request connectionRequest, offset int)
+}
+
+func newConnectionServer(t *testing.T, respond func(w http.ResponseWriter, request connectionRequest, offset int)) (*connectionServer, *GqlClient) {
+	s := &connectionServer{respond: respond}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request connectionRequest
+		json.NewDecoder(r.Body).Decode(&request)
+		s.mu.Lock()
+		s.requests = append(s.requests, request)
+		s.mu.Unlock()
+		offset := 0
+		if after, ok := request.Variables["after"].(string); ok {
+			offset, _ = strconv.Atoi(after)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		s.respond(w, request, offset)
+	}))
+	t.Cleanup(server.Close)
+	return s, NewClient(ClientConfig{QueryURL: server.URL})
+}
+
+func (s *connectionServer) afters() []interface{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	afters := []interface{}{}
+	for _, request := range s.requests {
+		afters = append(afters, request.Variables["after"])
+	}
+	return afters
+}
+
+// userPages serves count users in pages of first users.
+func userPages(count int) func(w http.ResponseWriter, request connectionRequest, offset int) {
+	return func(w http.ResponseWriter, request connectionRequest, offset int) {
+		first := int(request.Variables["first"].(float64))
+		edges := []map[string]interface{}{}
+		for i := offset; i < offset+first && i < count; i++ {
+			edges = append(edges, map[string]interface{}{"cursor": strconv.Itoa(i), "node": map[string]interface{}{"id": strconv.Itoa(i), "name": fmt.Sprintf("user%d", i)}})
+		}
+		end := offset + len(edges)
+		json.NewEncoder(w).Encode(map[string]interface{}{"data": map[string]interface{}{"users": map[string]interface{}{
+			"edges":    edges,
+			"pageInfo": map[string]interface{}{"hasNextPage": end < count, "endCursor": strconv.Itoa(end)},
+		}}})
+	}
+}
+
+func TestConnectionNodes(t *testing.T) {
+	server, client := newConnectionServer(t, userPages(5))
+	first, role := 2, "admin"
+	users := client.Query.SelectUsersNodes(context.Background(), &first, &role, UserFields().Name())
+	if len(server.afters()) != 0 {
+		t.Error("the first page was fetched before Next")
+	}
+	names := []string{}
+	for users.Next() {
+		names = append(names, users.Node().Name)
+	}
+	if err := users.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"user0", "user1", "user2", "user3", "user4"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("got users %v, want %v", names, want)
+	}
+	if got, want := server.afters(), []interface{}{nil, "2", "4"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got after variables %v, want %v", got, want)
+	}
+	request := server.requests[0]
+	if request.Variables["role"] != "admin" {
+		t.Errorf("got variables %v", request.Variables)
+	}
+	if !strings.Contains(request.Query, "{ edges { node { name } } pageInfo { hasNextPage endCursor } }") {
+		t.Errorf("got query %s", request.Query)
+	}
+	if users.Next() {
+		t.Error("Next fetched a page after the last page")
+	}
+}
+
+func TestConnectionEmpty(t *testing.T) {
+	server, client := newConnectionServer(t, userPages(0))
+	first := 2
+	users := client.Query.UsersNodes(context.Background(), &first, nil, "{ id }")
+	if users.Next() {
+		t.Errorf("got user %+v", users.Node())
+	}
+	if users.Err() != nil || len(server.afters()) != 1 {
+		t.Errorf("got error %v after %d requests", users.Err(), len(server.afters()))
+	}
+}
+
+// TestConnectionNullableFields checks that null edges, nodes and page
+// infos end the pages instead of failing.
+func TestConnectionNullableFields(t *testing.T) {
+	server, client := newConnectionServer(t, func(w http.ResponseWriter, request connectionRequest, offset int) {
+		if offset == 0 {
+			w.Write([]byte(`{"data": {"posts": {
+				"edges": [{"node": {"id": "1", "title": "first"}}, null, {"node": null}, {"node": {"id": "2", "title": "second"}}],
+				"pageInfo": {"hasNextPage": true, "endCursor": 4}
+			}}}`))
+			return
+		}
+		w.Write([]byte(`{"data": {"posts": {"edges": [{"node": {"id": "3", "title": "third"}}], "pageInfo": null}}}`))
+	})
+	posts := client.Query.SelectPostsNodes(context.Background(), 4, PostFields().Id().Title())
+	titles := []string{}
+	for posts.Next() {
+		titles = append(titles, posts.Node().Title)
+	}
+	if err := posts.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"first", "second", "third"}; !reflect.DeepEqual(titles, want) {
+		t.Errorf("got posts %v, want %v", titles, want)
+	}
+	if got, want := server.afters(), []interface{}{nil, "4"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got after variables %v, want %v", got, want)
+	}
+}
+
+func TestConnectionRepeatedCursor(t *testing.T) {
+	server, client := newConnectionServer(t, func(w http.ResponseWriter, request connectionRequest, offset int) {
+		w.Write([]byte(`{"data": {"tags": {"edges": [{"node": "go"}], "pageInfo": {"hasNextPage": true, "endCursor": "1"}}}}`))
+	})
+	tags := client.Query.TagsNodes(context.Background(), nil, "")
+	count := 0
+	for tags.Next() {
+		if tags.Node() != "go" {
+			t.Errorf("got tag %q", tags.Node())
+		}
+		count++
+	}
+	if count != 2 || len(server.afters()) != 2 {
+		t.Errorf("got %d tags and %d requests, want the iteration to stop when the cursor repeats", count, len(server.afters()))
+	}
+}
+
+func TestConnectionError(t *testing.T) {
+	pages := userPages(5)
+	server, client := newConnectionServer(t, func(w http.ResponseWriter, request connectionRequest, offset int) {
+		if offset > 0 {
+			w.Write([]byte(`{"errors": [{"message": "rate limited"}]}`))
+			return
+		}
+		pages(w, request, offset)
+	})
+	first := 2
+	users := client.Query.UsersNodes(context.Background(), &first, nil, "{ name }")
+	count := 0
+	for users.Next() {
+		count++
+	}
+	var errs GraphQLErrors
+	if !errors.As(users.Err(), &errs) || errs[0].Message != "rate limited" {
+		t.Errorf("got error %v, want the graphql error", users.Err())
+	}
+	if count != 2 || len(server.afters()) != 2 {
+		t.Errorf("got %d users and %d requests", count, len(server.afters()))
+	}
+	if users.Next() {
+		t.Error("Next continued after an error")
+	}
+}
+
+func TestConnectionContextCanceled(t *testing.T) {
+	server, client := newConnectionServer(t, userPages(5))
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	first := 2
+	users := client.Query.UsersNodes(ctx, &first, nil, "{ name }")
+	count := 0
+	for users.Next() {
+		count++
+		cancel()
+	}
+	if !errors.Is(users.Err(), context.Canceled) {
+		t.Errorf("got error %v, want %v", users.Err(), context.Canceled)
+	}
+	if count != 2 || len(server.afters()) != 1 {
+		t.Errorf("got %d users and %d requests, want the first page", count, len(server.afters()))
+	}
+}
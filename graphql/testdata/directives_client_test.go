@@ -0,0 +1,56 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestFieldDirectives(t *testing.T) {
+	field, ok := reflect.TypeOf(User{}).FieldByName("DisplayName")
+	if !ok {
+		t.Fatal("the @goField name is not used")
+	}
+	if got := field.Tag; got != `json:"fullName" db:"full_name"` {
+		t.Errorf("got tag %s", got)
+	}
+	var user User
+	if err := json.Unmarshal([]byte(`{"fullName": "Ada Lovelace"}`), &user); err != nil {
+		t.Fatal(err)
+	}
+	if user.DisplayName != "Ada Lovelace" {
+		t.Errorf("got user %+v", user)
+	}
+}
+
+func TestDeprecatedArguments(t *testing.T) {
+	var body struct {
+		Query     string                 `json:"query"`
+		Variables map[string]interface{} `json:"variables"`
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&body)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"data": {"user": {"id": "1", "fullName": "Ada Lovelace", "role": "MEMBER"}}}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(ClientConfig{QueryURL: server.URL})
+	user, err := client.Query.SelectUser(context.Background(), "1", nil, UserFields().Id().FullName().Role())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if user.DisplayName != "Ada Lovelace" || user.Role != RoleMember {
+		t.Errorf("got user %+v", user)
+	}
+	if !strings.Contains(body.Query, "includeDrafts: $includeDrafts") {
+		t.Errorf("got query %s", body.Query)
+	}
+	if value, ok := body.Variables["includeDrafts"]; !ok || value != nil {
+		t.Errorf("got variables %v", body.Variables)
+	}
+}
@@ -0,0 +1,35 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestStrictEnums(t *testing.T) {
+	if _, err := json.Marshal(Status("ARCHIVED")); err == nil || !strings.Contains(err.Error(), `"ARCHIVED" is not a valid Status`) {
+		t.Errorf("got marshal error %v", err)
+	}
+	var status Status
+	if err := json.Unmarshal([]byte(`"DONE"`), &status); err != nil || status != StatusDone {
+		t.Errorf("got %q, %v", status, err)
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"data": {"task": {"id": "1", "status": "ARCHIVED"}}}`))
+	}))
+	defer server.Close()
+	client := NewClient(ClientConfig{QueryURL: server.URL})
+	_, err := client.Query.Task(context.Background(), StatusDone, "{ id status }")
+	if err == nil || !strings.Contains(err.Error(), `"ARCHIVED" is not a valid Status`) {
+		t.Errorf("got error %v, want the unknown enum value", err)
+	}
+	_, err = client.Query.Task(context.Background(), Status("ARCHIVED"), "{ id status }")
+	if err == nil || !strings.Contains(err.Error(), `"ARCHIVED" is not a valid Status`) {
+		t.Errorf("got error %v, want the unknown enum argument", err)
+	}
+}
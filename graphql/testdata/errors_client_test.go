@@ -0,0 +1,131 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+)
+
+func errorServer(t *testing.T, handler http.HandlerFunc) *GqlClient {
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	return NewClient(ClientConfig{QueryURL: server.URL})
+}
+
+func TestPartialData(t *testing.T) {
+	client := errorServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{
+			"data": {"post": {"id": "1", "title": "Hello", "author": null}},
+			"errors": [
+				{"message": "not allowed", "path": ["post", "author"], "locations": [{"line": 3, "column": 5}], "extensions": {"code": "FORBIDDEN"}},
+				{"message": "slow down", "extensions": {"code": "RATE_LIMITED"}}
+			]
+		}`))
+	})
+	post, err := client.Query.Post(context.Background(), "1", "{ id title author { name } }")
+	if post == nil || post.Title != "Hello" || post.Author != nil {
+		t.Errorf("got post %+v, want the partial data", post)
+	}
+	if err == nil || err.Error() != "post.author: not allowed; slow down" {
+		t.Fatalf("got error %v", err)
+	}
+	var graphqlErrors GraphQLErrors
+	if !errors.As(err, &graphqlErrors) || len(graphqlErrors) != 2 {
+		t.Fatalf("got error %#v, want GraphQLErrors", err)
+	}
+	if graphqlErrors[1].Message != "slow down" || graphqlErrors[1].Code() != "RATE_LIMITED" {
+		t.Errorf("got second error %+v", graphqlErrors[1])
+	}
+
+	var first *GraphQLError
+	if !errors.As(err, &first) || first.Message != "not allowed" {
+		t.Errorf("got first error %+v", first)
+	}
+	if len(first.Locations) != 1 || first.Locations[0] != (GraphQLErrorLocation{Line: 3, Column: 5}) {
+		t.Errorf("got locations %+v", first.Locations)
+	}
+	if len(first.Path) != 2 || first.Path[1] != "author" {
+		t.Errorf("got path %v", first.Path)
+	}
+
+	target := CodeError{Code: "RATE_LIMITED"}
+	if !errors.As(err, &target) || target.Err != graphqlErrors[1] {
+		t.Errorf("got code error %+v, want the rate limited error", target)
+	}
+	if !HasErrorCode(err, "FORBIDDEN") || HasErrorCode(err, "NOT_FOUND") {
+		t.Error("the errors were not matched by their code")
+	}
+}
+
+func TestErrorResponses(t *testing.T) {
+	tests := map[string]struct {
+		status int
+		body   string
+		want   string
+	}{
+		"errors without data": {
+			status: http.StatusOK,
+			body:   `{"data": null, "errors": [{"message": "post not found", "path": ["post"], "extensions": {"code": "NOT_FOUND"}}]}`,
+			want:   "post: post not found",
+		},
+		"errors with an error status": {
+			status: http.StatusBadRequest,
+			body:   `{"errors": [{"message": "syntax error", "locations": [{"line": 1, "column": 2}]}]}`,
+			want:   "syntax error",
+		},
+		"error status without errors": {
+			status: http.StatusBadGateway,
+			body:   `<h1>Bad Gateway</h1>`,
+			want:   "server returned a non-200 status code: 502",
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			client := errorServer(t, func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(test.status)
+				w.Write([]byte(test.body))
+			})
+			post, err := client.Query.Post(context.Background(), "1", "{ id }")
+			if err == nil || err.Error() != test.want {
+				t.Fatalf("got error %v, want %q", err, test.want)
+			}
+			if post != nil {
+				t.Errorf("got post %+v", post)
+			}
+		})
+	}
+}
+
+// TestConcurrentErrors checks that the errors of concurrent requests are
+// not mixed up.
+func TestConcurrentErrors(t *testing.T) {
+	client := errorServer(t, func(w http.ResponseWriter, r *http.Request) {
+		var request struct {
+			Variables struct {
+				ID string `json:"id"`
+			} `json:"variables"`
+		}
+		json.NewDecoder(r.Body).Decode(&request)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"errors": []map[string]interface{}{{"message": "post " + request.Variables.ID}},
+		})
+	})
+	ids := []string{"1", "2", "3", "4", "5", "6", "7", "8"}
+	var wg sync.WaitGroup
+	for _, id := range ids {
+		wg.Add(1)
+		go func(id string) {
+			defer wg.Done()
+			_, err := client.Query.Post(context.Background(), id, "{ id }")
+			if err == nil || err.Error() != "post "+id {
+				t.Errorf("got error %v for post %s", err, id)
+			}
+		}(id)
+	}
+	wg.Wait()
+}
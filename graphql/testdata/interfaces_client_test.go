@@ -0,0 +1,75 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestInterfaceDispatch(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"data": {"nodes": [
+			{"__typename": "User", "id": "1", "name": "Ada", "friends": [{"__typename": "Bot", "id": "2", "model": "R2"}, null]},
+			{"__typename": "Bot", "id": "2", "model": "R2"},
+			{"__typename": "Alien", "id": "3"},
+			{"id": "4"}
+		]}}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(ClientConfig{QueryURL: server.URL})
+	nodes, err := client.Query.SelectNodes(context.Background(), NodeFields().Id())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(nodes) != 4 {
+		t.Fatalf("got %d nodes, want 4", len(nodes))
+	}
+
+	user, ok := nodes[0].Node.(*User)
+	if !ok || user.GetId() != "1" || *user.Name != "Ada" {
+		t.Fatalf("got first node %#v, want the user", nodes[0].Node)
+	}
+	if len(user.Friends) != 2 || user.Friends[1] != nil {
+		t.Fatalf("got friends %#v", user.Friends)
+	}
+	if bot, ok := user.Friends[0].Node.(*Bot); !ok || bot.Model != "R2" {
+		t.Errorf("got friend %#v, want the bot", user.Friends[0].Node)
+	}
+	if bot, ok := nodes[1].Node.(*Bot); !ok || bot.GetId() != "2" {
+		t.Errorf("got second node %#v, want the bot", nodes[1].Node)
+	}
+	if unknown, ok := nodes[2].Node.(*UnknownNode); !ok || unknown.Typename != "Alien" || unknown.GetId() != "3" {
+		t.Errorf("got third node %#v, want an unknown node", nodes[2].Node)
+	}
+	if unknown, ok := nodes[3].Node.(*UnknownNode); !ok || unknown.Typename != "" || unknown.GetId() != "4" {
+		t.Errorf("got fourth node %#v, want an unknown node", nodes[3].Node)
+	}
+	var named Named = user
+	if *named.GetName() != "Ada" {
+		t.Errorf("got name %q", *named.GetName())
+	}
+}
+
+func TestInterfaceInstanceJSON(t *testing.T) {
+	var instance NodeInstance
+	if err := json.Unmarshal([]byte(`null`), &instance); err != nil || instance.Node != nil {
+		t.Fatalf("got %#v, %v for null", instance, err)
+	}
+	if err := json.Unmarshal([]byte(`{"__typename": "Bot", "id": "2", "model": "R2"}`), &instance); err != nil {
+		t.Fatal(err)
+	}
+	data, err := json.Marshal(instance)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != `{"id":"2","model":"R2"}` {
+		t.Errorf("got %s", data)
+	}
+	if err := json.Unmarshal([]byte(`{"__typename": "Bot", "id": 2}`), &instance); err == nil {
+		t.Error("an invalid implementation was decoded")
+	}
+}
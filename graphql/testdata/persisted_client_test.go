@@ -0,0 +1,165 @@
+package client
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync"
+	"testing"
+)
+
+type apqRequest struct {
+	Query      string                 `json:"query"`
+	Variables  map[string]interface{} `json:"variables"`
+	Extensions struct {
+		PersistedQuery *struct {
+			Version    int    `json:"version"`
+			Sha256Hash string `json:"sha256Hash"`
+		} `json:"persistedQuery"`
+	} `json:"extensions"`
+}
+
+// apqServer is a server supporting automatic persisted queries, the
+// requests it received are returned.
+func apqServer(t *testing.T, config ClientConfig, notFound string) (*GqlClient, func() []apqRequest) {
+	var mu sync.Mutex
+	requests := []apqRequest{}
+	queries := map[string]string{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request apqRequest
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		requests = append(requests, request)
+		w.Header().Set("Content-Type", "application/json")
+		if persisted := request.Extensions.PersistedQuery; persisted != nil {
+			if request.Query == "" {
+				if _, ok := queries[persisted.Sha256Hash]; !ok {
+					w.Write([]byte(notFound))
+					return
+				}
+			} else {
+				hash := sha256.Sum256([]byte(request.Query))
+				if hex.EncodeToString(hash[:]) != persisted.Sha256Hash {
+					w.Write([]byte(`{"errors": [{"message": "provided sha does not match query"}]}`))
+					return
+				}
+				queries[persisted.Sha256Hash] = request.Query
+			}
+		}
+		w.Write([]byte(`{"data": {"post": {"id": "1", "title": "Hello"}, "likePost": {"id": "1", "likes": 2}}}`))
+	}))
+	t.Cleanup(server.Close)
+	config.QueryURL = server.URL
+	config.MutationURL = server.URL
+	return NewClient(config), func() []apqRequest {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]apqRequest{}, requests...)
+	}
+}
+
+func TestPersistedQueries(t *testing.T) {
+	notFound := map[string]string{
+		"not found":     `{"errors": [{"message": "PersistedQueryNotFound", "extensions": {"code": "PERSISTED_QUERY_NOT_FOUND"}}]}`,
+		"not supported": `{"errors": [{"message": "PersistedQueryNotSupported"}]}`,
+	}
+	for name, body := range notFound {
+		t.Run(name, func(t *testing.T) {
+			client, requests := apqServer(t, ClientConfig{PersistedQueries: true}, body)
+			for i := 0; i < 2; i++ {
+				response, err := client.GetPost(context.Background(), "1")
+				if err != nil {
+					t.Fatal(err)
+				}
+				if response.Post.Title != "Hello" {
+					t.Errorf("got response %+v", response)
+				}
+			}
+			all := requests()
+			if len(all) != 3 {
+				t.Fatalf("got %d requests, want the hash, the hash with the query and the hash", len(all))
+			}
+			if all[0].Query != "" || all[1].Query != GetPostDocument || all[2].Query != "" {
+				t.Errorf("got queries %q, %q and %q", all[0].Query, all[1].Query, all[2].Query)
+			}
+			for _, request := range all {
+				if request.Extensions.PersistedQuery == nil || request.Extensions.PersistedQuery.Version != 1 {
+					t.Errorf("got request %+v without the persisted query extension", request)
+				}
+				if request.Variables["id"] != "1" {
+					t.Errorf("got variables %v", request.Variables)
+				}
+			}
+		})
+	}
+}
+
+// TestPersistedQueryManifest checks that the hashes sent by the client
+// are the ids of the manifest written next to the generated client.
+func TestPersistedQueryManifest(t *testing.T) {
+	data, err := os.ReadFile("testdata/manifest.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var manifest struct {
+		Operations []struct {
+			ID   string `json:"id"`
+			Name string `json:"name"`
+			Body string `json:"body"`
+		} `json:"operations"`
+	}
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		t.Fatal(err)
+	}
+	ids := map[string]string{}
+	for _, operation := range manifest.Operations {
+		ids[operation.Name] = operation.ID
+	}
+
+	client, requests := apqServer(t, ClientConfig{PersistedQueries: true}, `{"errors": [{"message": "PersistedQueryNotFound"}]}`)
+	if _, err := client.GetPost(context.Background(), "1"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.LikePost(context.Background(), "1"); err != nil {
+		t.Fatal(err)
+	}
+	all := requests()
+	if len(all) != 4 || all[0].Extensions.PersistedQuery == nil || all[2].Extensions.PersistedQuery == nil {
+		t.Fatalf("got requests %+v", all)
+	}
+	if got := all[0].Extensions.PersistedQuery.Sha256Hash; got != ids["GetPost"] {
+		t.Errorf("got GetPost hash %s, want the manifest id %s", got, ids["GetPost"])
+	}
+	if got := all[2].Extensions.PersistedQuery.Sha256Hash; got != ids["LikePost"] {
+		t.Errorf("got LikePost hash %s, want the manifest id %s", got, ids["LikePost"])
+	}
+}
+
+func TestPersistedQueriesErrors(t *testing.T) {
+	client, requests := apqServer(t, ClientConfig{PersistedQueries: true}, `{"errors": [{"message": "not allowed", "extensions": {"code": "FORBIDDEN"}}]}`)
+	if _, err := client.GetPost(context.Background(), "1"); !HasErrorCode(err, "FORBIDDEN") {
+		t.Errorf("got error %v, want the server error", err)
+	}
+	if got := len(requests()); got != 1 {
+		t.Errorf("got %d requests, other errors must not be retried with the query", got)
+	}
+}
+
+func TestPersistedQueriesDisabled(t *testing.T) {
+	client, requests := apqServer(t, ClientConfig{}, "")
+	if _, err := client.Query.Post(context.Background(), "1", "{ id title }"); err != nil {
+		t.Fatal(err)
+	}
+	all := requests()
+	if len(all) != 1 || all[0].Query == "" || all[0].Extensions.PersistedQuery != nil {
+		t.Errorf("got requests %+v, want the query without the persisted query extension", all)
+	}
+}
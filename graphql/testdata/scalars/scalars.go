@@ -0,0 +1,20 @@
+// Package scalars encodes the Timestamp scalar of the generated client
+// tests as unix seconds.
+package scalars
+
+import (
+	"encoding/json"
+	"time"
+)
+
+func MarshalTimestamp(t time.Time) ([]byte, error) {
+	return json.Marshal(t.Unix())
+}
+
+func UnmarshalTimestamp(data []byte) (time.Time, error) {
+	var seconds int64
+	if err := json.Unmarshal(data, &seconds); err != nil {
+		return time.Time{}, err
+	}
+	return time.Unix(seconds, 0).UTC(), nil
+}
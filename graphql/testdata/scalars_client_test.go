@@ -0,0 +1,68 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestMappedScalars(t *testing.T) {
+	var request struct {
+		Variables map[string]json.RawMessage `json:"variables"`
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"data": {"schedule": {
+			"id": "6f1c",
+			"count": 9007199254740993,
+			"payload": {"tags": ["a", "b"]},
+			"at": 1700000000,
+			"history": [1600000000, null],
+			"extra": [1, "two"]
+		}}}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(ClientConfig{MutationURL: server.URL})
+	at := Timestamp{Value: time.Unix(1700000000, 0)}
+	event, err := client.Mutation.SelectSchedule(context.Background(), at, []*string{StringP("6f1c")}, EventFields().Id().Count().Payload().At().History().Extra())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := string(request.Variables["at"]); got != "1700000000" {
+		t.Errorf("got at variable %s, want the marshalled timestamp", got)
+	}
+	if got := string(request.Variables["ids"]); got != `["6f1c"]` {
+		t.Errorf("got ids variable %s", got)
+	}
+
+	var id string = event.Id
+	if id != "6f1c" {
+		t.Errorf("got id %q", id)
+	}
+	var count *int64 = event.Count
+	if count == nil || *count != 9007199254740993 {
+		t.Errorf("got count %v, want the exact int64", count)
+	}
+	var payload *json.RawMessage = event.Payload
+	if payload == nil || string(*payload) != `{"tags": ["a", "b"]}` {
+		t.Errorf("got payload %v", payload)
+	}
+	if !event.At.Value.Equal(time.Unix(1700000000, 0)) {
+		t.Errorf("got at %v", event.At.Value)
+	}
+	if len(event.History) != 2 || !event.History[0].Value.Equal(time.Unix(1600000000, 0)) || event.History[1] != nil {
+		t.Errorf("got history %v", event.History)
+	}
+	var extra interface{} = event.Extra
+	if items, ok := extra.([]interface{}); !ok || len(items) != 2 {
+		t.Errorf("got extra %#v, want the unmapped scalar decoded as interface{}", extra)
+	}
+}
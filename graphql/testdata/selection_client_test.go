@@ -0,0 +1,88 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestSelectionString(t *testing.T) {
+	tests := map[string]struct {
+		selection interface{ String() string }
+		want      string
+	}{
+		"empty": {
+			selection: UserFields(),
+			want:      "{ __typename }",
+		},
+		"nested with aliases": {
+			selection: UserFields().Id().Alias("displayName").Name().Friends(UserFields().Name()),
+			want:      "{ id displayName: name friends { name } }",
+		},
+		"optional arguments are skipped": {
+			selection: UserFields().Posts(nil, nil, nil, PostFields().Title()),
+			want:      "{ posts { title } }",
+		},
+		"argument literals": {
+			selection: UserFields().Posts(IntP(2), &StatusPublished, &PostFilter{
+				Tags:     []*string{StringP(`say "hi"`)},
+				MinLikes: IntP(10),
+			}, PostFields().Id().Status()),
+			want: `{ posts(first: 2, status: Published, filter: {tags: ["say \"hi\""], minLikes: 10}) { id status } }`,
+		},
+		"nil selection": {
+			selection: UserFields().Posts(nil, nil, nil, nil),
+			want:      "{ posts { __typename } }",
+		},
+		"union fragments": {
+			selection: SearchResultFields().OnUser(UserFields().Name()).OnPost(PostFields().Title()),
+			want:      "{ __typename ... on User { name } ... on Post { title } }",
+		},
+		"interface fields and fragments": {
+			selection: NodeFields().Id().OnPost(PostFields().Typename().Title()),
+			want:      "{ __typename id ... on Post { __typename title } }",
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			if got := test.selection.String(); got != test.want {
+				t.Errorf("got %s, want %s", got, test.want)
+			}
+		})
+	}
+}
+
+func TestSelectQuery(t *testing.T) {
+	var request struct {
+		Query     string                 `json:"query"`
+		Variables map[string]interface{} `json:"variables"`
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"data": {"user": {"id": "1", "posts": [{"title": "Hello", "status": "Published"}]}}}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(ClientConfig{QueryURL: server.URL})
+	user, err := client.Query.SelectUser(context.Background(), "1", UserFields().Id().Posts(IntP(1), nil, nil, PostFields().Title().Status()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "user(id: $id, ) { id posts(first: 1) { title status } }"
+	if !strings.Contains(request.Query, want) {
+		t.Errorf("got query %s, want it to contain %s", request.Query, want)
+	}
+	if request.Variables["id"] != "1" {
+		t.Errorf("got variables %v", request.Variables)
+	}
+	if user.Id != "1" || len(user.Posts) != 1 || user.Posts[0].Title != "Hello" || user.Posts[0].Status != StatusPublished {
+		t.Errorf("got user %+v", user)
+	}
+}
@@ -0,0 +1,27 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestExtendedTypes(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"data": {"user": {"id": "1", "name": "Ada", "posts": [{"id": "2", "title": "Hello"}]}}}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(ClientConfig{QueryURL: server.URL})
+	user, err := client.Query.SelectUser(context.Background(), "1", UserFields().Id().Name().Posts(PostFields().Id().Title()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if user.Name != "Ada" || len(user.Posts) != 1 || user.Posts[0].Title != "Hello" {
+		t.Errorf("got user %+v", user)
+	}
+	// the query extension is generated as a method of Query.
+	var _ func(context.Context, *int, *PostSelection) ([]Post, error) = client.Query.SelectPosts
+}
@@ -0,0 +1,317 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+type wsMessage struct {
+	ID      string          `json:"id,omitempty"`
+	Type    string          `json:"type"`
+	Payload json.RawMessage `json:"payload,omitempty"`
+}
+
+type wsRequest struct {
+	Query     string                 `json:"query"`
+	Variables map[string]interface{} `json:"variables"`
+}
+
+// subscriptionServer serves every WebSocket connection with handle, the
+// connections are upgraded with the first supported protocol.
+func subscriptionServer(t *testing.T, config ClientConfig, protocols []string, handle func(t *testing.T, conn *websocket.Conn, r *http.Request)) *GqlClient {
+	upgrader := websocket.Upgrader{Subprotocols: protocols}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		defer conn.Close()
+		handle(t, conn, r)
+	}))
+	t.Cleanup(server.Close)
+	config.SubscriptionURL = "ws" + strings.TrimPrefix(server.URL, "http")
+	return NewClient(config)
+}
+
+func readMessage(t *testing.T, conn *websocket.Conn, messageType string) wsMessage {
+	t.Helper()
+	var message wsMessage
+	if err := conn.ReadJSON(&message); err != nil {
+		t.Errorf("reading %s: %v", messageType, err)
+		return message
+	}
+	if message.Type != messageType {
+		t.Errorf("got message %s %s, want %s", message.Type, message.Payload, messageType)
+	}
+	return message
+}
+
+// acknowledge reads the connection_init and subscription start messages
+// and returns the subscription id.
+func acknowledge(t *testing.T, conn *websocket.Conn, startType string) string {
+	t.Helper()
+	readMessage(t, conn, "connection_init")
+	conn.WriteJSON(wsMessage{Type: "connection_ack"})
+	start := readMessage(t, conn, startType)
+	if start.ID == "" {
+		t.Error("got no subscription id")
+	}
+	return start.ID
+}
+
+func collect(t *testing.T, results <-chan MessageAddedResult) []MessageAddedResult {
+	t.Helper()
+	all := []MessageAddedResult{}
+	timeout := time.After(10 * time.Second)
+	for {
+		select {
+		case result, ok := <-results:
+			if !ok {
+				return all
+			}
+			all = append(all, result)
+		case <-timeout:
+			t.Fatal("the subscription was not closed")
+		}
+	}
+}
+
+func TestSubscriptionTransportWS(t *testing.T) {
+	var init wsMessage
+	var header http.Header
+	var request wsRequest
+	client := subscriptionServer(t, ClientConfig{
+		DefaultHTTPHeaders:      map[string]string{"X-Tenant": "acme"},
+		SubscriptionInitPayload: map[string]interface{}{"token": "secret"},
+		SubscriptionKeepAlive:   10 * time.Millisecond,
+	}, []string{"graphql-transport-ws", "graphql-ws"}, func(t *testing.T, conn *websocket.Conn, r *http.Request) {
+		header = r.Header
+		init = readMessage(t, conn, "connection_init")
+		conn.WriteJSON(wsMessage{Type: "connection_ack"})
+		start := readMessage(t, conn, "subscribe")
+		json.Unmarshal(start.Payload, &request)
+		// the keep alive pings are answered before sending the payloads.
+		readMessage(t, conn, "ping")
+		conn.WriteJSON(wsMessage{Type: "pong"})
+		conn.WriteJSON(wsMessage{Type: "ping"})
+		readMessage(t, conn, "pong")
+		conn.WriteJSON(wsMessage{ID: start.ID, Type: "next", Payload: json.RawMessage(`{"data": {"messageAdded": {"id": "1", "text": "hello"}}}`)})
+		conn.WriteJSON(wsMessage{ID: start.ID, Type: "next", Payload: json.RawMessage(`{"errors": [{"message": "not allowed"}]}`)})
+		conn.WriteJSON(wsMessage{ID: start.ID, Type: "next", Payload: json.RawMessage(`{"data": {"messageAdded": {"id": "2", "text": "bye"}}}`)})
+		conn.WriteJSON(wsMessage{ID: start.ID, Type: "complete"})
+	})
+
+	results, err := client.Subscription.SelectMessageAdded(context.Background(), "general", MessageFields().Id().Text())
+	if err != nil {
+		t.Fatal(err)
+	}
+	all := collect(t, results)
+	if len(all) != 3 {
+		t.Fatalf("got %d results, want 3", len(all))
+	}
+	if all[0].Err != nil || all[0].Data.Text != "hello" {
+		t.Errorf("got first result %+v", all[0])
+	}
+	if all[1].Err == nil || all[1].Err.Error() != "not allowed" {
+		t.Errorf("got second result %+v, want the payload error", all[1])
+	}
+	if all[2].Err != nil || all[2].Data.Text != "bye" {
+		t.Errorf("got third result %+v", all[2])
+	}
+
+	if got := header.Get("Sec-WebSocket-Protocol"); got != "graphql-transport-ws, graphql-ws" {
+		t.Errorf("got protocols %q", got)
+	}
+	if got := header.Get("X-Tenant"); got != "acme" {
+		t.Errorf("got X-Tenant header %q", got)
+	}
+	if string(init.Payload) != `{"token":"secret"}` {
+		t.Errorf("got init payload %s", init.Payload)
+	}
+	if !strings.Contains(request.Query, "messageAdded(room: $room, ) { id text }") || request.Variables["room"] != "general" {
+		t.Errorf("got request %+v", request)
+	}
+}
+
+func TestSubscriptionLegacyFallback(t *testing.T) {
+	stopped := make(chan []string, 1)
+	client := subscriptionServer(t, ClientConfig{}, []string{"graphql-ws"}, func(t *testing.T, conn *websocket.Conn, r *http.Request) {
+		id := acknowledge(t, conn, "start")
+		conn.WriteJSON(wsMessage{Type: "ka"})
+		conn.WriteJSON(wsMessage{ID: id, Type: "data", Payload: json.RawMessage(`{"data": {"messageAdded": {"id": "1", "text": "hello"}}}`)})
+		types := []string{}
+		for {
+			var message wsMessage
+			if err := conn.ReadJSON(&message); err != nil {
+				break
+			}
+			types = append(types, strings.Replace(message.Type+":"+message.ID, id, "id", 1))
+		}
+		stopped <- types
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	results, err := client.Subscription.MessageAdded(ctx, "general", "{ text }")
+	if err != nil {
+		t.Fatal(err)
+	}
+	result := <-results
+	if result.Err != nil || result.Data.Text != "hello" {
+		t.Errorf("got result %+v", result)
+	}
+	cancel()
+	if all := collect(t, results); len(all) != 0 {
+		t.Errorf("got results %+v after cancelling", all)
+	}
+	select {
+	case types := <-stopped:
+		if strings.Join(types, ",") != "stop:id,connection_terminate:" {
+			t.Errorf("got messages %v, want the subscription to be stopped", types)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("the connection was not closed")
+	}
+}
+
+func TestSubscriptionErrors(t *testing.T) {
+	tests := map[string]struct {
+		messages []wsMessage
+		want     string
+	}{
+		"error message": {
+			messages: []wsMessage{{Type: "error", Payload: json.RawMessage(`[{"message": "unknown room"}]`)}},
+			want:     "unknown room",
+		},
+		// the read error of the dropped connection is returned.
+		"connection dropped": {},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			client := subscriptionServer(t, ClientConfig{SubscriptionMaxReconnects: -1}, []string{"graphql-transport-ws"}, func(t *testing.T, conn *websocket.Conn, r *http.Request) {
+				id := acknowledge(t, conn, "subscribe")
+				for _, message := range test.messages {
+					message.ID = id
+					conn.WriteJSON(message)
+				}
+				conn.UnderlyingConn().Close()
+			})
+			results, err := client.Subscription.MessageAdded(context.Background(), "general", "{ text }")
+			if err != nil {
+				t.Fatal(err)
+			}
+			all := collect(t, results)
+			if len(all) != 1 || all[0].Err == nil || (test.want != "" && all[0].Err.Error() != test.want) {
+				t.Errorf("got results %+v, want the error %q", all, test.want)
+			}
+		})
+	}
+}
+
+func TestSubscriptionConnectionError(t *testing.T) {
+	client := subscriptionServer(t, ClientConfig{}, []string{"graphql-transport-ws"}, func(t *testing.T, conn *websocket.Conn, r *http.Request) {
+		readMessage(t, conn, "connection_init")
+		conn.WriteJSON(wsMessage{Type: "connection_error", Payload: json.RawMessage(`{"message": "forbidden"}`)})
+	})
+	_, err := client.Subscription.MessageAdded(context.Background(), "general", "{ text }")
+	if err == nil || !strings.Contains(err.Error(), "forbidden") {
+		t.Fatalf("got error %v, want the connection error", err)
+	}
+}
+
+func TestSubscriptionReconnect(t *testing.T) {
+	var connections int32
+	ids := make(chan string, 2)
+	// reconnects are enabled by default.
+	client := subscriptionServer(t, ClientConfig{}, []string{"graphql-transport-ws"}, func(t *testing.T, conn *websocket.Conn, r *http.Request) {
+		id := acknowledge(t, conn, "subscribe")
+		ids <- id
+		if atomic.AddInt32(&connections, 1) == 1 {
+			conn.UnderlyingConn().Close()
+			return
+		}
+		conn.WriteJSON(wsMessage{ID: id + "0", Type: "next", Payload: json.RawMessage(`{"data": {"messageAdded": {"id": "0", "text": "other"}}}`)})
+		conn.WriteJSON(wsMessage{ID: id, Type: "next", Payload: json.RawMessage(`{"data": {"messageAdded": {"id": "1", "text": "again"}}}`)})
+		conn.WriteJSON(wsMessage{ID: id, Type: "complete"})
+	})
+	results, err := client.Subscription.MessageAdded(context.Background(), "general", "{ text }")
+	if err != nil {
+		t.Fatal(err)
+	}
+	all := collect(t, results)
+	if len(all) != 1 || all[0].Err != nil || all[0].Data.Text != "again" {
+		t.Errorf("got results %+v", all)
+	}
+	if got := atomic.LoadInt32(&connections); got != 2 {
+		t.Fatalf("got %d connections, want 2", got)
+	}
+	if first, second := <-ids, <-ids; first == second {
+		t.Errorf("got the subscription id %q twice", first)
+	}
+}
+
+func TestSubscriptionReconnectLimit(t *testing.T) {
+	var connections int32
+	client := subscriptionServer(t, ClientConfig{SubscriptionMaxReconnects: 2}, []string{"graphql-transport-ws"}, func(t *testing.T, conn *websocket.Conn, r *http.Request) {
+		acknowledge(t, conn, "subscribe")
+		atomic.AddInt32(&connections, 1)
+		conn.UnderlyingConn().Close()
+	})
+	results, err := client.Subscription.MessageAdded(context.Background(), "general", "{ text }")
+	if err != nil {
+		t.Fatal(err)
+	}
+	all := collect(t, results)
+	if len(all) != 1 || all[0].Err == nil {
+		t.Errorf("got results %+v, want the read error", all)
+	}
+	if got := atomic.LoadInt32(&connections); got != 3 {
+		t.Errorf("got %d connections, want 3", got)
+	}
+}
+
+func TestSubscriptionFrames(t *testing.T) {
+	text := strings.Repeat("a", 70000)
+	client := subscriptionServer(t, ClientConfig{SubscriptionMaxReconnects: -1}, []string{"graphql-transport-ws"}, func(t *testing.T, conn *websocket.Conn, r *http.Request) {
+		id := acknowledge(t, conn, "subscribe")
+		// the payloads use the 16 and 64 bit extended lengths and the
+		// second one is fragmented with a ping in between.
+		conn.WriteJSON(wsMessage{ID: id, Type: "next", Payload: json.RawMessage(`{"data": {"messageAdded": {"id": "1", "text": "` + text[:200] + `"}}}`)})
+		writer, err := conn.NextWriter(websocket.TextMessage)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		writer.Write([]byte(`{"id": "` + id + `", "type": "next", "payload": {"data": {"messageAdded": {"id": "2", "text": "`))
+		conn.WriteControl(websocket.PingMessage, []byte("alive"), time.Now().Add(time.Second))
+		writer.Write([]byte(text + `"}}}}`))
+		writer.Close()
+		conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseGoingAway, "restarting"))
+		conn.ReadMessage()
+	})
+	results, err := client.Subscription.MessageAdded(context.Background(), "general", "{ text }")
+	if err != nil {
+		t.Fatal(err)
+	}
+	all := collect(t, results)
+	if len(all) != 3 {
+		t.Fatalf("got %d results, want 3", len(all))
+	}
+	if all[0].Err != nil || all[0].Data.Text != text[:200] {
+		t.Errorf("got first result %+v", all[0])
+	}
+	if all[1].Err != nil || all[1].Data.Text != text {
+		t.Errorf("got second result error %v", all[1].Err)
+	}
+	if all[2].Err == nil || !strings.Contains(all[2].Err.Error(), "1001 restarting") {
+		t.Errorf("got third result %+v, want the close error", all[2])
+	}
+}
@@ -0,0 +1,81 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type recordingClient struct {
+	requests []*http.Request
+	client   *http.Client
+}
+
+func (c *recordingClient) Do(req *http.Request) (*http.Response, error) {
+	c.requests = append(c.requests, req)
+	return c.client.Do(req)
+}
+
+func TestTransport(t *testing.T) {
+	var header http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header = r.Header
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("X-Request-Id", "42")
+		w.Write([]byte(`{"data": {"post": {"id": "1", "title": "Hello"}}}`))
+	}))
+	defer server.Close()
+
+	httpClient := &recordingClient{client: server.Client()}
+	client := NewClient(ClientConfig{
+		QueryURL:           server.URL,
+		DefaultHTTPHeaders: map[string]string{"X-Tenant": "default", "X-Version": "1"},
+		HTTPClient:         httpClient,
+		RequestEditor: func(ctx context.Context, req *http.Request) error {
+			if req.Header.Get("X-Abort") != "" {
+				return errors.New("aborted")
+			}
+			req.Header.Set("Authorization", "Bearer token")
+			return nil
+		},
+	})
+
+	var responseHeader http.Header
+	ctx := WithRequestHeaders(context.Background(), http.Header{"x-tenant": {"acme"}})
+	ctx = WithRequestHeaders(ctx, http.Header{"X-Trace": {"abc"}})
+	ctx = WithResponseHeaders(ctx, &responseHeader)
+	post, err := client.Query.Post(ctx, "1", "{ id title }")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if post.Title != "Hello" {
+		t.Errorf("got post %+v", post)
+	}
+	if len(httpClient.requests) != 1 {
+		t.Fatalf("got %d requests through the HTTP client, want 1", len(httpClient.requests))
+	}
+	for key, want := range map[string]string{
+		"Content-Type":  "application/json; charset=utf-8",
+		"Authorization": "Bearer token",
+		"X-Tenant":      "acme",
+		"X-Version":     "1",
+		"X-Trace":       "abc",
+	} {
+		if got := header.Get(key); got != want {
+			t.Errorf("got %s header %q, want %q", key, got, want)
+		}
+	}
+	if got := responseHeader.Get("X-Request-Id"); got != "42" {
+		t.Errorf("got response header %q", got)
+	}
+
+	ctx = WithRequestHeaders(context.Background(), http.Header{"X-Abort": {"1"}})
+	if _, err := client.Query.Post(ctx, "1", "{ id }"); err == nil || err.Error() != "aborted" {
+		t.Errorf("got error %v, want the request editor error", err)
+	}
+	if len(httpClient.requests) != 1 {
+		t.Errorf("the aborted request was sent")
+	}
+}
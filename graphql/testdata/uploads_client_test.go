@@ -0,0 +1,231 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+type uploadRequest struct {
+	contentType string
+	parts       []string
+	operations  map[string]interface{}
+	fileMap     map[string][]string
+	files       map[string]string
+}
+
+// uploadServer records the multipart requests, the first bytes of the
+// file part named slow are signaled on received before the rest is read.
+func uploadServer(t *testing.T, config ClientConfig, received chan<- struct{}) (*GqlClient, func() []uploadRequest) {
+	var mu sync.Mutex
+	requests := []uploadRequest{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		request := uploadRequest{contentType: r.Header.Get("Content-Type"), files: map[string]string{}}
+		defer func() {
+			mu.Lock()
+			requests = append(requests, request)
+			mu.Unlock()
+		}()
+		w.Header().Set("Content-Type", "application/json")
+		if !strings.HasPrefix(request.contentType, "multipart/form-data") {
+			json.NewDecoder(r.Body).Decode(&request.operations)
+			w.Write([]byte(`{"data": {"rename": {"id": "1", "filename": "renamed"}}}`))
+			return
+		}
+		reader, err := r.MultipartReader()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		for {
+			part, err := reader.NextPart()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			request.parts = append(request.parts, part.FormName())
+			var contents []byte
+			if part.FileName() == "slow" && received != nil {
+				first := make([]byte, 5)
+				io.ReadFull(part, first)
+				received <- struct{}{}
+				contents = first
+			}
+			rest, _ := io.ReadAll(part)
+			contents = append(contents, rest...)
+			switch part.FormName() {
+			case "operations":
+				json.Unmarshal(contents, &request.operations)
+			case "map":
+				json.Unmarshal(contents, &request.fileMap)
+			default:
+				request.files[part.FormName()] = part.FileName() + ";" + part.Header.Get("Content-Type") + ";" + string(contents)
+			}
+		}
+		query, _ := request.operations["query"].(string)
+		switch {
+		case strings.Contains(query, "singleUpload"):
+			w.Write([]byte(`{"data": {"singleUpload": {"id": "1", "filename": "a.png"}}}`))
+		case strings.Contains(query, "multipleUpload"):
+			w.Write([]byte(`{"data": {"multipleUpload": [{"id": "1", "filename": "a.txt"}, {"id": "2", "filename": "b.txt"}]}}`))
+		default:
+			w.Write([]byte(`{"data": {"createPost": {"id": "1", "title": "Hello"}}}`))
+		}
+	}))
+	t.Cleanup(server.Close)
+	config.MutationURL = server.URL
+	return NewClient(config), func() []uploadRequest {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]uploadRequest{}, requests...)
+	}
+}
+
+func variables(request uploadRequest) map[string]interface{} {
+	variables, _ := request.operations["variables"].(map[string]interface{})
+	return variables
+}
+
+func TestSingleUpload(t *testing.T) {
+	client, requests := uploadServer(t, ClientConfig{}, nil)
+	file, err := client.Mutation.SingleUpload(context.Background(), Upload{File: strings.NewReader("png"), Filename: `my "cat".png`, ContentType: "image/png"}, "{ id filename }")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if file.Filename != "a.png" {
+		t.Errorf("got file %+v", file)
+	}
+	request := requests()[0]
+	if !strings.HasPrefix(request.contentType, "multipart/form-data; boundary=") {
+		t.Errorf("got content type %q", request.contentType)
+	}
+	if !reflect.DeepEqual(request.parts, []string{"operations", "map", "0"}) {
+		t.Errorf("got parts %v, want the operations, the map and the file", request.parts)
+	}
+	if value, ok := variables(request)["file"]; !ok || value != nil {
+		t.Errorf("got variables %v, want the file to be null", variables(request))
+	}
+	if !reflect.DeepEqual(request.fileMap, map[string][]string{"0": {"variables.file"}}) {
+		t.Errorf("got map %v", request.fileMap)
+	}
+	if got := request.files["0"]; got != `my "cat".png;image/png;png` {
+		t.Errorf("got file %q", got)
+	}
+}
+
+func TestMultipleUploads(t *testing.T) {
+	client, requests := uploadServer(t, ClientConfig{}, nil)
+	files, err := client.Mutation.MultipleUpload(context.Background(), []Upload{
+		{File: strings.NewReader("a"), Filename: "a.txt"},
+		{File: strings.NewReader("b"), Filename: "b.txt"},
+	}, "{ id filename }")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 2 {
+		t.Errorf("got files %+v", files)
+	}
+	request := requests()[0]
+	if !reflect.DeepEqual(request.fileMap, map[string][]string{"0": {"variables.files.0"}, "1": {"variables.files.1"}}) {
+		t.Errorf("got map %v", request.fileMap)
+	}
+	if request.files["0"] != "a.txt;application/octet-stream;a" || request.files["1"] != "b.txt;application/octet-stream;b" {
+		t.Errorf("got files %v", request.files)
+	}
+	if got, ok := variables(request)["files"].([]interface{}); !ok || len(got) != 2 || got[0] != nil || got[1] != nil {
+		t.Errorf("got variables %v, want the files to be null", variables(request))
+	}
+}
+
+func TestInputUploads(t *testing.T) {
+	client, requests := uploadServer(t, ClientConfig{}, nil)
+	_, err := client.Mutation.CreatePost(context.Background(), PostInput{
+		Title:       "Hello",
+		Cover:       &Upload{File: strings.NewReader("cover"), Filename: "cover.png"},
+		Attachments: []*Upload{nil, {File: strings.NewReader("doc"), Filename: "doc.pdf"}},
+	}, "{ id }")
+	if err != nil {
+		t.Fatal(err)
+	}
+	request := requests()[0]
+	want := map[string][]string{"0": {"variables.input.cover"}, "1": {"variables.input.attachments.1"}}
+	if !reflect.DeepEqual(request.fileMap, want) {
+		t.Errorf("got map %v, want %v", request.fileMap, want)
+	}
+	if request.files["0"] != "cover.png;application/octet-stream;cover" || request.files["1"] != "doc.pdf;application/octet-stream;doc" {
+		t.Errorf("got files %v", request.files)
+	}
+	input, _ := variables(request)["input"].(map[string]interface{})
+	if input["title"] != "Hello" || input["cover"] != nil {
+		t.Errorf("got input %v", input)
+	}
+}
+
+func TestUploadIsStreamed(t *testing.T) {
+	received := make(chan struct{})
+	client, _ := uploadServer(t, ClientConfig{}, received)
+	reader, writer := io.Pipe()
+	go func() {
+		writer.Write([]byte("first"))
+		// the rest of the file is only written once the server received
+		// the beginning, a buffered body would never be sent.
+		select {
+		case <-received:
+			writer.Write([]byte(" second"))
+			writer.Close()
+		case <-time.After(5 * time.Second):
+			writer.CloseWithError(errors.New("the upload was not streamed"))
+		}
+	}()
+	if _, err := client.Mutation.SingleUpload(context.Background(), Upload{File: reader, Filename: "slow"}, "{ id }"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestUploadReaderError(t *testing.T) {
+	client, _ := uploadServer(t, ClientConfig{}, nil)
+	_, err := client.Mutation.SingleUpload(context.Background(), Upload{File: errReader{}, Filename: "a.png"}, "{ id }")
+	if err == nil || !strings.Contains(err.Error(), "disk failure") {
+		t.Errorf("got error %v, want the reader error", err)
+	}
+}
+
+type errReader struct{}
+
+func (errReader) Read([]byte) (int, error) {
+	return 0, errors.New("disk failure")
+}
+
+func TestRequestsWithoutUploads(t *testing.T) {
+	client, requests := uploadServer(t, ClientConfig{}, nil)
+	if _, err := client.Mutation.Rename(context.Background(), "1", "renamed", "{ id }"); err != nil {
+		t.Fatal(err)
+	}
+	if got := requests()[0].contentType; got != "application/json; charset=utf-8" {
+		t.Errorf("got content type %q, want JSON", got)
+	}
+}
+
+// TestPersistedQueryUploads checks that the query is sent with the
+// uploads, streamed files can not be sent again.
+func TestPersistedQueryUploads(t *testing.T) {
+	client, requests := uploadServer(t, ClientConfig{PersistedQueries: true}, nil)
+	if _, err := client.Mutation.SingleUpload(context.Background(), Upload{File: strings.NewReader("png"), Filename: "a.png"}, "{ id }"); err != nil {
+		t.Fatal(err)
+	}
+	all := requests()
+	if len(all) != 1 || !strings.Contains(all[0].operations["query"].(string), "singleUpload") {
+		t.Errorf("got requests %+v, want a single request with the query", all)
+	}
+}
@@ -0,0 +1,74 @@
+package openapi
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+// generateTestClient generates the client of a schema file in a module of
+// its own, the files are copied into the module by destination name.
+func generateTestClient(t *testing.T, schemaFile string, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	if err := GenerateGoSDK(schemaFile, dir); err != nil {
+		t.Fatal(err)
+	}
+	contents := map[string][]byte{"go.mod": []byte("module client\n\ngo 1.16\n")}
+	for name, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		contents[name] = data
+	}
+	for name, data := range contents {
+		if err := os.WriteFile(filepath.Join(dir, name), data, 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+// runGo runs the go command in the directory of a generated client.
+func runGo(t *testing.T, dir string, args ...string) {
+	t.Helper()
+	goBin, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("go is not installed")
+	}
+	cmd := exec.Command(goBin, args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "GOFLAGS=-mod=mod", "GOWORK=off")
+	if output, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("go %v: %v\n%s", args, err, output)
+	}
+}
+
+// testClient vets the client generated from a schema file and runs the
+// tests of the test file against it, if any.
+func testClient(t *testing.T, schemaFile, testFile string) {
+	t.Helper()
+	files := map[string]string{}
+	if testFile != "" {
+		files["client_test.go"] = testFile
+	}
+	dir := generateTestClient(t, schemaFile, files)
+	runGo(t, dir, "vet", ".")
+	if testFile != "" {
+		runGo(t, dir, "test", "-count=1", ".")
+	}
+}
+
+func TestGenerateSampleClient(t *testing.T) {
+	testClient(t, "../openapi-sample.yaml", "")
+}
+
+func TestGeneratedMiddlewares(t *testing.T) {
+	testClient(t, "testdata/errors.yaml", "testdata/middlewares_client_test.go")
+}
+
+func TestGeneratedRetries(t *testing.T) {
+	testClient(t, "testdata/retry.yaml", "testdata/retry_client_test.go")
+}
@@ -0,0 +1,328 @@
+package openapi
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// Server is an OpenAPI 3 server object.
+type Server struct {
+	URL         string                    `json:"url" yaml:"url"`
+	Description string                    `json:"description" yaml:"description"`
+	Variables   map[string]ServerVariable `json:"variables" yaml:"variables"`
+}
+
+// ServerVariable is a variable used for server url template substitution.
+type ServerVariable struct {
+	Default     string   `json:"default" yaml:"default"`
+	Enum        []string `json:"enum" yaml:"enum"`
+	Description string   `json:"description" yaml:"description"`
+}
+
+// Components holds the reusable objects of an OpenAPI 3 schema.
+type Components struct {
+	Schemas         map[string]Property      `json:"schemas" yaml:"schemas"`
+	Responses       map[string]Property      `json:"responses" yaml:"responses"`
+	Parameters      map[string]PathParameter `json:"parameters" yaml:"parameters"`
+	RequestBodies   map[string]RequestBody   `json:"requestBodies" yaml:"requestBodies"`
+	SecuritySchemes map[string]SecurityV3    `json:"securitySchemes" yaml:"securitySchemes"`
+}
+
+// SecurityV3 is an OpenAPI 3 security scheme.
+type SecurityV3 struct {
+	Type         string `json:"type" yaml:"type"`
+	Description  string `json:"description" yaml:"description"`
+	Name         string `json:"name" yaml:"name"`
+	In           string `json:"in" yaml:"in"`
+	Scheme       string `json:"scheme" yaml:"scheme"`
+	BearerFormat string `json:"bearerFormat" yaml:"bearerFormat"`
+	Flows        struct {
+		Implicit          *OAuthFlow `json:"implicit" yaml:"implicit"`
+		Password          *OAuthFlow `json:"password" yaml:"password"`
+		ClientCredentials *OAuthFlow `json:"clientCredentials" yaml:"clientCredentials"`
+		AuthorizationCode *OAuthFlow `json:"authorizationCode" yaml:"authorizationCode"`
+	} `json:"flows" yaml:"flows"`
+	OpenIDConnectURL string `json:"openIdConnectUrl" yaml:"openIdConnectUrl"`
+}
+
+// OAuthFlow is an OpenAPI 3 OAuth2 flow.
+type OAuthFlow struct {
+	AuthorizationURL string            `json:"authorizationUrl" yaml:"authorizationUrl"`
+	TokenURL         string            `json:"tokenUrl" yaml:"tokenUrl"`
+	RefreshURL       string            `json:"refreshUrl" yaml:"refreshUrl"`
+	Scopes           map[string]string `json:"scopes" yaml:"scopes"`
+}
+
+// toSecurityDefinition converts the security scheme into its swagger 2.0
+// equivalent, http schemes keep the http type with the scheme as flow.
+func (s SecurityV3) toSecurityDefinition() SecurityDefinition {
+	definition := SecurityDefinition{
+		Type:        s.Type,
+		Description: s.Description,
+		Name:        s.Name,
+		In:          s.In,
+	}
+	switch s.Type {
+	case "http":
+		definition.Flow = strings.ToLower(s.Scheme)
+	case "oauth2":
+		// the client credentials flow is preferred since it is the only
+		// flow the generated clients can perform on their own.
+		flows := []struct {
+			name string
+			flow *OAuthFlow
+		}{
+			{"application", s.Flows.ClientCredentials},
+			{"password", s.Flows.Password},
+			{"accessCode", s.Flows.AuthorizationCode},
+			{"implicit", s.Flows.Implicit},
+		}
+		for _, f := range flows {
+			if f.flow == nil {
+				continue
+			}
+			definition.Flow = f.name
+			definition.AuthorizationURL = f.flow.AuthorizationURL
+			definition.TokenURL = f.flow.TokenURL
+			definition.Scopes = f.flow.Scopes
+			break
+		}
+	}
+	return definition
+}
+
+// RequestBody is an OpenAPI 3 operation request body.
+type RequestBody struct {
+	Ref         string               `json:"$ref" yaml:"$ref"`
+	Description string               `json:"description" yaml:"description"`
+	Required    bool                 `json:"required" yaml:"required"`
+	Content     map[string]MediaType `json:"content" yaml:"content"`
+}
+
+// MediaType describes the schema of a request / response content type.
+type MediaType struct {
+	Schema *Property `json:"schema" yaml:"schema"`
+}
+
+// IsOpenAPIV3 returns true if the schema is an OpenAPI 3.x document.
+func (s *OpenAPISchema) IsOpenAPIV3() bool {
+	return strings.HasPrefix(s.OpenAPI, "3.")
+}
+
+// normalizeOpenAPIV3 converts the OpenAPI 3 specific parts of the schema
+// (components, request bodies, content keyed responses and servers) into
+// their swagger 2.0 equivalent so that the same generator can be used for
+// both formats.
+func normalizeOpenAPIV3(schema *OpenAPISchema) error {
+	if schema.Definitions == nil {
+		schema.Definitions = make(map[string]Property)
+	}
+	if schema.Responses == nil {
+		schema.Responses = make(map[string]Property)
+	}
+	if schema.Parameters == nil {
+		schema.Parameters = make(map[string]PathParameter)
+	}
+	for name, property := range schema.Components.Schemas {
+		schema.Definitions[name] = property
+	}
+	for name, response := range schema.Components.Responses {
+		schema.Responses[name], _ = contentToSchema(response)
+	}
+	for name, param := range schema.Components.Parameters {
+		schema.Parameters[name] = param
+	}
+	if schema.SecurityDefinitions == nil {
+		schema.SecurityDefinitions = make(map[string]SecurityDefinition)
+	}
+	for name, securityScheme := range schema.Components.SecuritySchemes {
+		schema.SecurityDefinitions[name] = securityScheme.toSecurityDefinition()
+	}
+	for path, pathItem := range schema.Paths {
+		for httpMethod, pathInfo := range pathItem {
+			produces := []string{}
+			for code, response := range pathInfo.Responses {
+				var contentTypes []string
+				response, contentTypes = contentToSchema(response)
+				pathInfo.Responses[code] = response
+				produces = appendUnique(produces, contentTypes...)
+			}
+			if len(pathInfo.Produces) == 0 {
+				pathInfo.Produces = produces
+			}
+			if pathInfo.RequestBody != nil {
+				requestBody, err := resolveRequestBody(schema, *pathInfo.RequestBody)
+				if err != nil {
+					return fmt.Errorf("%s %s: %w", strings.ToUpper(httpMethod), path, err)
+				}
+				contentTypes := sortedContentTypes(requestBody.Content)
+				if len(pathInfo.Consumes) == 0 {
+					pathInfo.Consumes = contentTypes
+				}
+				contentType := preferredContentType(contentTypes)
+				mediaType := requestBody.Content[contentType]
+				if isFormContentType(contentType) && mediaType.Schema != nil {
+					pathInfo.Parameters = append(pathInfo.Parameters, formDataParameters(schema, *mediaType.Schema)...)
+					pathItem[httpMethod] = pathInfo
+					continue
+				}
+				param := PathParameter{
+					Description: requestBody.Description,
+					In:          "body",
+					Name:        "body",
+					Required:    requestBody.Required,
+				}
+				if mediaType.Schema != nil {
+					param.Schema = *mediaType.Schema
+				}
+				pathInfo.Parameters = append(pathInfo.Parameters, param)
+			}
+			pathItem[httpMethod] = pathInfo
+		}
+	}
+	return applyServer(schema)
+}
+
+// isFormContentType returns true for multipart and url encoded form
+// content types.
+func isFormContentType(contentType string) bool {
+	contentType = strings.ToLower(contentType)
+	return strings.HasPrefix(contentType, "multipart/form-data") ||
+		strings.HasPrefix(contentType, "application/x-www-form-urlencoded")
+}
+
+// formDataParameters converts the properties of a form request body schema
+// into swagger 2.0 formData parameters, binary properties become files.
+func formDataParameters(schema *OpenAPISchema, property Property) []PathParameter {
+	if name := strings.TrimPrefix(property.Ref, "#/components/schemas/"); property.Ref != "" {
+		property = schema.Components.Schemas[name]
+	}
+	names := []string{}
+	for name := range property.Properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	params := []PathParameter{}
+	for _, name := range names {
+		prop := property.Properties[name]
+		param := PathParameter{
+			Description: prop.Description,
+			In:          "formData",
+			Name:        name,
+			Required:    property.IsRequired(name),
+			Type:        prop.Type,
+			Format:      prop.Format,
+			Items:       prop.Items,
+		}
+		if prop.Ref != "" {
+			param.Schema = prop
+		}
+		if isBinaryProperty(prop) {
+			param.Type = "file"
+			param.Format = ""
+		}
+		if prop.Type == "array" && prop.Items != nil && isBinaryProperty(*prop.Items) {
+			param.Items = &Property{Type: "file"}
+		}
+		params = append(params, param)
+	}
+	return params
+}
+
+func isBinaryProperty(property Property) bool {
+	return property.Type == "string" && property.Format == "binary"
+}
+
+// contentToSchema sets the response schema from the preferred content type
+// of an OpenAPI 3 response, it also returns the response content types.
+func contentToSchema(response Property) (Property, []string) {
+	if len(response.Content) == 0 {
+		return response, nil
+	}
+	contentTypes := sortedContentTypes(response.Content)
+	if response.Schema == nil {
+		response.Schema = response.Content[preferredContentType(contentTypes)].Schema
+	}
+	return response, contentTypes
+}
+
+func resolveRequestBody(schema *OpenAPISchema, requestBody RequestBody) (RequestBody, error) {
+	if requestBody.Ref == "" {
+		return requestBody, nil
+	}
+	name := strings.TrimPrefix(requestBody.Ref, "#/components/requestBodies/")
+	resolved, ok := schema.Components.RequestBodies[name]
+	if !ok {
+		return requestBody, fmt.Errorf("request body %s not found", requestBody.Ref)
+	}
+	return resolveRequestBody(schema, resolved)
+}
+
+// applyServer sets the host, base path and schemes of the schema from
+// the first OpenAPI 3 server.
+func applyServer(schema *OpenAPISchema) error {
+	if len(schema.Servers) == 0 {
+		return nil
+	}
+	server := schema.Servers[0]
+	serverURL := server.URL
+	for name, variable := range server.Variables {
+		serverURL = strings.ReplaceAll(serverURL, "{"+name+"}", variable.Default)
+	}
+	u, err := url.Parse(serverURL)
+	if err != nil {
+		return fmt.Errorf("invalid server url %s: %w", server.URL, err)
+	}
+	if schema.Host == "" {
+		schema.Host = u.Host
+	}
+	if schema.BasePath == "" {
+		schema.BasePath = u.Path
+	}
+	if len(schema.Schemes) == 0 && u.Scheme != "" {
+		schema.Schemes = []string{u.Scheme}
+	}
+	return nil
+}
+
+func sortedContentTypes(content map[string]MediaType) []string {
+	contentTypes := []string{}
+	for contentType := range content {
+		contentTypes = append(contentTypes, contentType)
+	}
+	sort.Strings(contentTypes)
+	return contentTypes
+}
+
+// preferredContentType returns the json content type if present or the
+// first content type.
+func preferredContentType(contentTypes []string) string {
+	for _, contentType := range contentTypes {
+		lower := strings.ToLower(contentType)
+		if strings.Contains(lower, "application/json") || strings.HasSuffix(lower, "+json") {
+			return contentType
+		}
+	}
+	if len(contentTypes) == 0 {
+		return ""
+	}
+	return contentTypes[0]
+}
+
+func appendUnique(list []string, values ...string) []string {
+	for _, value := range values {
+		exists := false
+		for _, item := range list {
+			if item == value {
+				exists = true
+				break
+			}
+		}
+		if !exists {
+			list = append(list, value)
+		}
+	}
+	return list
+}
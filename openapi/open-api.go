@@ -16,24 +16,90 @@ import (
 )
 
 type Property struct {
-	Description          string              `json:"description" yaml:"description"`
-	Properties           map[string]Property `json:"properties" yaml:"properties"`
-	Required             []string            `json:"required" yaml:"required"`
-	Type                 string              `json:"type" yaml:"type"`
-	XGoPackage           string              `json:"x-go-package" yaml:"x-go-package"`
-	Ref                  string              `json:"$ref" yaml:"$ref"`
-	Format               string              `json:"format" yaml:"format"`
-	XGoName              string              `json:"x-go-name" yaml:"x-go-name"`
-	AdditionalProperties *Property           `json:"additionalProperties" yaml:"additionalProperties"`
-	Items                *Property           `json:"items" yaml:"items"`
-	XML                  struct {
-		Name    string `json:"name" yaml:"name"`
-		Wrapped bool   `json:"wrapped" yaml:"wrapped"`
-	} `json:"xml"`
-	Default struct {
-		Description string `json:"description" yaml:"description"`
-	} `json:"default"`
-	Schema *Property `json:"schema" yaml:"schema"`
+	Description          string               `json:"description" yaml:"description"`
+	Properties           map[string]Property  `json:"properties" yaml:"properties"`
+	Required             []string             `json:"required" yaml:"required"`
+	Type                 SchemaType           `json:"type" yaml:"type"`
+	XGoPackage           string               `json:"x-go-package" yaml:"x-go-package"`
+	Ref                  string               `json:"$ref" yaml:"$ref"`
+	Format               string               `json:"format" yaml:"format"`
+	XGoName              string               `json:"x-go-name" yaml:"x-go-name"`
+	AdditionalProperties *Property            `json:"additionalProperties" yaml:"additionalProperties"`
+	Items                *Property            `json:"items" yaml:"items"`
+	XML                  XML                  `json:"xml" yaml:"xml"`
+	Default              interface{}          `json:"default" yaml:"default"`
+	Schema               *Property            `json:"schema" yaml:"schema"`
+	Content              map[string]MediaType `json:"content" yaml:"content"`
+	// Headers are the headers of a response.
+	Headers map[string]Property `json:"headers" yaml:"headers"`
+}
+
+// UnmarshalJSON implements json.Unmarshaler, it accepts boolean schemas
+// such as `additionalProperties: true` in addition to schema objects.
+func (p *Property) UnmarshalJSON(data []byte) error {
+	var boolSchema bool
+	if err := json.Unmarshal(data, &boolSchema); err == nil {
+		*p = Property{}
+		return nil
+	}
+	type property Property
+	return json.Unmarshal(data, (*property)(p))
+}
+
+// UnmarshalYAML implements yaml.Unmarshaler, it accepts boolean schemas
+// such as `additionalProperties: true` in addition to schema objects.
+func (p *Property) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var boolSchema bool
+	if err := unmarshal(&boolSchema); err == nil {
+		*p = Property{}
+		return nil
+	}
+	type property Property
+	return unmarshal((*property)(p))
+}
+
+// SchemaType is the type of a schema property, OpenAPI 3.1 allows the
+// type to be a list (e.g [string, "null"]) in which case the first
+// non null type is used.
+type SchemaType string
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (t *SchemaType) UnmarshalJSON(data []byte) error {
+	var types []string
+	if err := json.Unmarshal(data, &types); err == nil {
+		*t = firstNonNullType(types)
+		return nil
+	}
+	var typ string
+	if err := json.Unmarshal(data, &typ); err != nil {
+		return err
+	}
+	*t = SchemaType(typ)
+	return nil
+}
+
+// UnmarshalYAML implements yaml.Unmarshaler.
+func (t *SchemaType) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var types []string
+	if err := unmarshal(&types); err == nil {
+		*t = firstNonNullType(types)
+		return nil
+	}
+	var typ string
+	if err := unmarshal(&typ); err != nil {
+		return err
+	}
+	*t = SchemaType(typ)
+	return nil
+}
+
+func firstNonNullType(types []string) SchemaType {
+	for _, typ := range types {
+		if typ != "null" {
+			return SchemaType(typ)
+		}
+	}
+	return ""
 }
 
 func (p Property) IsRequired(str string) bool {
@@ -55,6 +121,7 @@ type Path struct {
 	Description string                `json:"description" yaml:"description"`
 	OperationID string                `json:"operationId" yaml:"operationId"`
 	Parameters  []PathParameter       `json:"parameters" yaml:"parameters"`
+	RequestBody *RequestBody          `json:"requestBody" yaml:"requestBody"`
 	Responses   map[string]Property   `json:"responses" yaml:"responses"`
 	Summary     string                `json:"summary" yaml:"summary"`
 	Tags        []string              `json:"tags" yaml:"tags"`
@@ -62,14 +129,106 @@ type Path struct {
 	Schemes     []string              `json:"schemes" yaml:"schemes"`
 	Consumes    []string              `json:"consumes" yaml:"consumes"`
 	Produces    []string              `json:"produces" yaml:"produces"`
+	// XRetryable makes the generated clients retry the operation even if
+	// its http method is not idempotent.
+	XRetryable bool `json:"x-retryable" yaml:"x-retryable"`
+	// XPagination declares the pagination of the operation.
+	XPagination *Pagination `json:"x-pagination" yaml:"x-pagination"`
+}
+
+// PathItem contains the operations available on a single path keyed by
+// their http method, path level parameters are merged into each operation.
+type PathItem map[string]Path
+
+// pathItem is the raw representation of a path item object.
+type pathItem struct {
+	Parameters []PathParameter `json:"parameters" yaml:"parameters"`
+	Get        *Path           `json:"get" yaml:"get"`
+	Put        *Path           `json:"put" yaml:"put"`
+	Post       *Path           `json:"post" yaml:"post"`
+	Delete     *Path           `json:"delete" yaml:"delete"`
+	Options    *Path           `json:"options" yaml:"options"`
+	Head       *Path           `json:"head" yaml:"head"`
+	Patch      *Path           `json:"patch" yaml:"patch"`
+	Trace      *Path           `json:"trace" yaml:"trace"`
+}
+
+func (p pathItem) toPathItem() PathItem {
+	item := PathItem{}
+	operations := map[string]*Path{
+		"get":     p.Get,
+		"put":     p.Put,
+		"post":    p.Post,
+		"delete":  p.Delete,
+		"options": p.Options,
+		"head":    p.Head,
+		"patch":   p.Patch,
+		"trace":   p.Trace,
+	}
+	for httpMethod, operation := range operations {
+		if operation == nil {
+			continue
+		}
+		// operation level parameters override path level parameters
+		// with the same name and location.
+		overridden := map[string]bool{}
+		for _, param := range operation.Parameters {
+			overridden[param.key()] = true
+		}
+		params := []PathParameter{}
+		for _, param := range p.Parameters {
+			if !overridden[param.key()] {
+				params = append(params, param)
+			}
+		}
+		operation.Parameters = append(params, operation.Parameters...)
+		item[httpMethod] = *operation
+	}
+	return item
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (p *PathItem) UnmarshalJSON(data []byte) error {
+	var item pathItem
+	if err := json.Unmarshal(data, &item); err != nil {
+		return err
+	}
+	*p = item.toPathItem()
+	return nil
+}
+
+// UnmarshalYAML implements yaml.Unmarshaler.
+func (p *PathItem) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var item pathItem
+	if err := unmarshal(&item); err != nil {
+		return err
+	}
+	*p = item.toPathItem()
+	return nil
 }
 
 type PathParameter struct {
-	Description string   `json:"description" yaml:"description"`
-	In          string   `json:"in" yaml:"in"`
-	Name        string   `json:"name" yaml:"name"`
-	Required    bool     `json:"required" yaml:"required"`
-	Schema      Property `json:"schema" yaml:"schema"`
+	Ref         string     `json:"$ref" yaml:"$ref"`
+	Description string     `json:"description" yaml:"description"`
+	In          string     `json:"in" yaml:"in"`
+	Name        string     `json:"name" yaml:"name"`
+	Required    bool       `json:"required" yaml:"required"`
+	Schema      Property   `json:"schema" yaml:"schema"`
+	Type        SchemaType `json:"type" yaml:"type"`
+	Format      string     `json:"format" yaml:"format"`
+	Items       *Property  `json:"items" yaml:"items"`
+	// CollectionFormat is the swagger 2.0 array serialization format.
+	CollectionFormat string `json:"collectionFormat" yaml:"collectionFormat"`
+	// Style and Explode are the OpenAPI 3 serialization options.
+	Style   string `json:"style" yaml:"style"`
+	Explode *bool  `json:"explode" yaml:"explode"`
+}
+
+func (p PathParameter) key() string {
+	if p.Ref != "" {
+		return p.Ref
+	}
+	return p.In + ":" + p.Name
 }
 
 type OpenAPISchema struct {
@@ -78,27 +237,34 @@ type OpenAPISchema struct {
 	Definitions         map[string]Property           `json:"definitions" yaml:"definitions"`
 	Host                string                        `json:"host" yaml:"host"`
 	Info                Info                          `json:"info" yaml:"info"`
-	Paths               map[string]map[string]Path    `json:"paths" yaml:"paths"`
+	Parameters          map[string]PathParameter      `json:"parameters" yaml:"parameters"`
+	Paths               map[string]PathItem           `json:"paths" yaml:"paths"`
 	Produces            []string                      `json:"produces" yaml:"produces"`
 	Responses           map[string]Property           `json:"responses" yaml:"responses"`
 	Schemes             []string                      `json:"schemes" yaml:"schemes"`
 	SecurityDefinitions map[string]SecurityDefinition `json:"securityDefinitions" yaml:"securityDefinitions"`
+	Security            []map[string][]string         `json:"security" yaml:"security"`
 	Swagger             string                        `json:"swagger" yaml:"swagger"`
+	OpenAPI             string                        `json:"openapi" yaml:"openapi"`
+	Servers             []Server                      `json:"servers" yaml:"servers"`
+	Components          Components                    `json:"components" yaml:"components"`
 	RefMap              map[string]string
 	RefPropertyMap      map[string]Property
+	ParameterRefMap     map[string]PathParameter
 	ApiPathsMap         map[string]map[string]map[string]Path
+	// Pagination contains the heuristics detecting paginated operations.
+	Pagination PaginationHeuristics `json:"-" yaml:"-"`
 }
 
 type SecurityDefinition struct {
-	Type             string `json:"type" yaml:"type"`
-	Name             string `json:"name" yaml:"name"`
-	In               string `json:"in" yaml:"in"`
-	AuthorizationURL string `json:"authorizationUrl" yaml:"authorizationUrl"`
-	Flow             string `json:"flow" yaml:"flow"`
-	Scopes           struct {
-		ReadPets  string `json:"read:pets" yaml:"read:pets"`
-		WritePets string `json:"write:pets" yaml:"write:pets"`
-	} `json:"scopes"`
+	Type             string            `json:"type" yaml:"type"`
+	Description      string            `json:"description" yaml:"description"`
+	Name             string            `json:"name" yaml:"name"`
+	In               string            `json:"in" yaml:"in"`
+	AuthorizationURL string            `json:"authorizationUrl" yaml:"authorizationUrl"`
+	TokenURL         string            `json:"tokenUrl" yaml:"tokenUrl"`
+	Flow             string            `json:"flow" yaml:"flow"`
+	Scopes           map[string]string `json:"scopes" yaml:"scopes"`
 }
 
 var (
@@ -118,6 +284,7 @@ var (
 		"float64":     "float64",
 		"interface{}": "interface{}",
 		"time.Time":   "time.Time",
+		"binary":      "io.Reader",
 	}
 
 	templateFuncs template.FuncMap = template.FuncMap{
@@ -130,27 +297,46 @@ var (
 		"toUpperCase": func(str string) string {
 			return strings.ToUpper(str)
 		},
-		"pathParameterToProperty": func(params []PathParameter) Property {
-			param := PathParameter{}
-			if len(params) > 0 {
-				param = params[0]
-			}
-			return Property{
-				Ref: param.Schema.Ref,
+		"bodyParameter": bodyParameter,
+		"operationParameters": func(schema *OpenAPISchema, operation Path) []OperationParameter {
+			return operationParameters(schema, operation)
+		},
+		"pathParameters": func(path string, params []PathParameter) []PathParameter {
+			return pathParameters(path, params)
+		},
+		"parameterTypeName": func(schema *OpenAPISchema, param PathParameter) TypeName {
+			return extractTypeName(schema, param.Property())
+		},
+		"goComment": func(name, description string) string {
+			description = strings.TrimSpace(description)
+			if description == "" {
+				return ""
 			}
+			return "// " + name + " " + strings.ReplaceAll(description, "\n", "\n// ") + "\n"
 		},
+		"toVariableName": toVariableName,
+		"pathFormat":     pathFormat,
 		"stringToInt": func(str string) int {
 			i, _ := strconv.Atoi(str)
 			return i
 		},
 		"extractResponseType": func(schema *OpenAPISchema, responseName string, responses map[string]Property) string {
 			fieldsMap := map[string]string{}
-			for _, response := range responses {
-				definition := extractRootDefinition(schema, response.Ref)
+			fieldsProperties := map[string]Property{}
+			responseTypes := map[TypeName]bool{}
+			for code, response := range responses {
+				if !isSuccessStatusCode(code) {
+					continue
+				}
+				definition := extractResponseDefinition(schema, response)
 				if definition == nil {
 					continue
 				}
+				if len(definition.Properties) == 0 {
+					responseTypes[extractTypeName(schema, *definition)] = true
+				}
 				for name, prop := range definition.Properties {
+					fieldsProperties[name] = prop
 					fieldType := extractTypeName(schema, prop)
 					prefix := ""
 					if !definition.IsRequired(name) && !fieldType.IsNullable() && !fieldType.IsBuiltIn() {
@@ -165,19 +351,50 @@ var (
 				}
 			}
 			if len(fieldsMap) == 0 {
+				// non object responses (e.g arrays) are used as is when all
+				// the success responses have the same type.
+				for typeName := range responseTypes {
+					if len(responseTypes) == 1 {
+						return typeName.String()
+					}
+				}
 				return "interface{}"
 			}
 			responseType := "struct { \n"
 			for fieldName, fieldType := range fieldsMap {
 				responseType += fmt.Sprintf(
-					"%s %s `json:\"%s,omitempty\"` \n",
+					"%s %s `json:\"%s,omitempty\" %s` \n",
 					strcase.ToCamel(fieldName),
 					fieldType,
 					fieldName,
+					xmlTag(schema, fieldName, fieldsProperties[fieldName]),
 				)
 			}
 			return responseType + "}"
 		},
+		"errorResponses": func(schema *OpenAPISchema, methodName string, responses map[string]Property) []ErrorResponse {
+			return errorResponses(schema, methodName, responses)
+		},
+		"formParameters": func(schema *OpenAPISchema, params []PathParameter) []OperationParameter {
+			return formParameters(schema, params)
+		},
+		"isMultipart":       isMultipart,
+		"hasFormParameters": hasFormParameters,
+		"securitySchemes": func(schema *OpenAPISchema) []SecurityScheme {
+			return securitySchemes(schema)
+		},
+		"hasDefaultResponse": func(responses []ErrorResponse) bool {
+			for _, response := range responses {
+				if response.Code == "default" {
+					return true
+				}
+			}
+			return false
+		},
+		"xmlTag":              xmlTag,
+		"operationPagination": operationPagination,
+		"hasPagination":       hasPagination,
+		"defaultBaseURL":      defaultBaseURL,
 		"pointerPrefix": func(property Property, fieldName string, typeName TypeName) TypeName {
 			if !property.IsRequired(fieldName) && !typeName.IsBuiltIn() && !typeName.IsNullable() {
 				return "*" + typeName
@@ -206,7 +423,7 @@ func (t TypeName) String() string {
 // extractTypeName is a helper function for extracting property type name
 // as Go type or custom type name.
 func extractTypeName(schema *OpenAPISchema, property Property) TypeName {
-	res := property.Type
+	res := string(property.Type)
 	if property.Format != "" {
 		res = property.Format
 	}
@@ -217,19 +434,17 @@ func extractTypeName(schema *OpenAPISchema, property Property) TypeName {
 		// return non-required types as pointers
 		res = strcase.ToCamel(refName)
 	}
+	if res == "" {
+		return "interface{}"
+	}
 	if property.Type != "array" && res != "object" {
 		return TypeName(res)
 	}
-	if property.Items != nil {
-		if property.Items.Type != "" {
-			res = property.Items.Type
-		}
-		if refName, ok := schema.RefMap[property.Items.Ref]; ok {
-			res = strcase.ToCamel(refName)
+	if property.Type == "array" {
+		if property.Items == nil {
+			return "[]interface{}"
 		}
-	}
-	if res != "object" {
-		return TypeName("[]" + res)
+		return TypeName("[]" + extractTypeName(schema, *property.Items))
 	}
 	// if property has additional properties then it is a map.
 	additionalProperties := property.AdditionalProperties
@@ -251,7 +466,7 @@ func extractRootDefinition(schema *OpenAPISchema, ref string) *Property {
 	if !ok {
 		return nil
 	}
-	if strings.Contains(ref, "definitions") {
+	if strings.Contains(ref, "definitions") || strings.Contains(ref, "components/schemas") {
 		return &definition
 	}
 	if definition.Schema == nil || definition.Schema.Ref == "" {
@@ -260,6 +475,30 @@ func extractRootDefinition(schema *OpenAPISchema, ref string) *Property {
 	return extractRootDefinition(schema, definition.Schema.Ref)
 }
 
+// extractResponseDefinition returns the schema definition of an operation
+// response which is either a reference or an inline schema.
+func extractResponseDefinition(schema *OpenAPISchema, response Property) *Property {
+	if response.Ref != "" {
+		return extractRootDefinition(schema, response.Ref)
+	}
+	if response.Schema == nil || response.Schema.Ref == "" {
+		return response.Schema
+	}
+	return extractRootDefinition(schema, response.Schema.Ref)
+}
+
+// resolveParameter returns the parameter referenced by a parameter ref.
+func resolveParameter(schema *OpenAPISchema, param PathParameter) (PathParameter, error) {
+	if param.Ref == "" {
+		return param, nil
+	}
+	resolved, ok := schema.ParameterRefMap[param.Ref]
+	if !ok {
+		return param, fmt.Errorf("parameter %s not found", param.Ref)
+	}
+	return resolveParameter(schema, resolved)
+}
+
 // LoadOpenApiSchema loads open api schema from api schema file.
 func LoadOpenApiSchema(filePath string) (*OpenAPISchema, error) {
 	fileContents, err := os.ReadFile(filePath)
@@ -267,9 +506,11 @@ func LoadOpenApiSchema(filePath string) (*OpenAPISchema, error) {
 		return nil, err
 	}
 	schema := OpenAPISchema{
-		RefMap:         make(map[string]string),
-		RefPropertyMap: make(map[string]Property),
-		ApiPathsMap:    make(map[string]map[string]map[string]Path),
+		RefMap:          make(map[string]string),
+		RefPropertyMap:  make(map[string]Property),
+		ParameterRefMap: make(map[string]PathParameter),
+		ApiPathsMap:     make(map[string]map[string]map[string]Path),
+		Pagination:      DefaultPaginationHeuristics(),
 	}
 	if strings.HasSuffix(filePath, ".yaml") || strings.HasSuffix(filePath, ".yml") {
 		err = yaml.Unmarshal(fileContents, &schema)
@@ -284,19 +525,53 @@ func LoadOpenApiSchema(filePath string) (*OpenAPISchema, error) {
 	} else {
 		return nil, fmt.Errorf("provide a valid json / yaml schema file")
 	}
+	if schema.IsOpenAPIV3() {
+		err = normalizeOpenAPIV3(&schema)
+		if err != nil {
+			return nil, err
+		}
+	}
 	for name, property := range schema.Definitions {
-		key := fmt.Sprintf("#/definitions/%s", name)
-		schema.RefMap[key] = name
-		schema.RefPropertyMap[key] = property
+		// schemas with properties but without an explicit type are objects.
+		if property.Type == "" && len(property.Properties) > 0 {
+			property.Type = "object"
+			schema.Definitions[name] = property
+		}
+		for _, key := range []string{"#/definitions/%s", "#/components/schemas/%s"} {
+			key = fmt.Sprintf(key, name)
+			schema.RefMap[key] = name
+			schema.RefPropertyMap[key] = property
+		}
 	}
 	for name, property := range schema.Responses {
-		key := fmt.Sprintf("#/responses/%s", name)
-		schema.RefMap[key] = name
-		schema.RefPropertyMap[key] = property
+		for _, key := range []string{"#/responses/%s", "#/components/responses/%s"} {
+			key = fmt.Sprintf(key, name)
+			schema.RefMap[key] = name
+			schema.RefPropertyMap[key] = property
+		}
+	}
+	for name, param := range schema.Parameters {
+		schema.ParameterRefMap[fmt.Sprintf("#/parameters/%s", name)] = param
+		schema.ParameterRefMap[fmt.Sprintf("#/components/parameters/%s", name)] = param
 	}
 	// extracting API paths based on path tags.
 	for path := range schema.Paths {
 		for httpMethod, pathInfo := range schema.Paths[path] {
+			for i, param := range pathInfo.Parameters {
+				pathInfo.Parameters[i], err = resolveParameter(&schema, param)
+				if err != nil {
+					return nil, fmt.Errorf("%s %s: %w", strings.ToUpper(httpMethod), path, err)
+				}
+			}
+			err = validatePathParameters(path, pathInfo.Parameters)
+			if err != nil {
+				return nil, fmt.Errorf("%s %s: %w", strings.ToUpper(httpMethod), path, err)
+			}
+			// operations without security requirements use the global
+			// requirements, an empty list disables security.
+			if pathInfo.Security == nil {
+				pathInfo.Security = schema.Security
+			}
 			for _, tag := range pathInfo.Tags {
 				if _, ok := schema.ApiPathsMap[tag]; !ok {
 					schema.ApiPathsMap[tag] = make(map[string]map[string]Path)
@@ -311,13 +586,41 @@ func LoadOpenApiSchema(filePath string) (*OpenAPISchema, error) {
 	return &schema, nil
 }
 
+// defaultBaseURL returns the base url of the api from the host, base path
+// and schemes of the schema, https is preferred when the api supports
+// several schemes. An empty string is returned if the host is unknown.
+func defaultBaseURL(schema *OpenAPISchema) string {
+	if schema.Host == "" {
+		return ""
+	}
+	scheme := "https"
+	if len(schema.Schemes) > 0 {
+		scheme = schema.Schemes[0]
+	}
+	for _, s := range schema.Schemes {
+		if s == "https" {
+			scheme = s
+		}
+	}
+	return scheme + "://" + schema.Host + strings.TrimSuffix(schema.BasePath, "/")
+}
+
 // GenerateGoSDK generates a Go api sdk from an openapi schema file.
 func GenerateGoSDK(schemaFile string, outDir string) error {
-	err := os.MkdirAll(outDir, 0700)
+	schema, err := LoadOpenApiSchema(schemaFile)
 	if err != nil {
 		return err
 	}
-	schema, err := LoadOpenApiSchema(schemaFile)
+	return GenerateClient(schema, outDir)
+}
+
+// GenerateClient generates a Go api sdk from a loaded schema.
+func GenerateClient(schema *OpenAPISchema, outDir string) error {
+	err := validatePagination(schema)
+	if err != nil {
+		return err
+	}
+	err = os.MkdirAll(outDir, 0700)
 	if err != nil {
 		return err
 	}
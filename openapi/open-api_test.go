@@ -0,0 +1,82 @@
+package openapi
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDefaultBaseURL(t *testing.T) {
+	tests := []struct {
+		name   string
+		schema string
+		want   string
+	}{
+		{"server variables", `
+openapi: 3.0.0
+info: {title: Test, version: 1.0.0}
+servers:
+  - url: https://{region}.example.com/{version}/
+    variables:
+      region: {default: eu}
+      version: {default: v1}
+  - url: https://staging.example.com
+paths: {}
+`, "https://eu.example.com/v1"},
+		{"relative server", `
+openapi: 3.0.0
+info: {title: Test, version: 1.0.0}
+servers:
+  - url: /v1
+paths: {}
+`, ""},
+		{"no servers", `
+openapi: 3.0.0
+info: {title: Test, version: 1.0.0}
+paths: {}
+`, ""},
+		{"swagger host", `
+swagger: "2.0"
+info: {title: Test, version: 1.0.0}
+host: api.example.com
+basePath: /v2
+schemes: [http, https]
+paths: {}
+`, "https://api.example.com/v2"},
+		{"swagger scheme", `
+swagger: "2.0"
+info: {title: Test, version: 1.0.0}
+host: localhost:8080
+schemes: [http]
+paths: {}
+`, "http://localhost:8080"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			schemaFile := filepath.Join(t.TempDir(), "schema.yaml")
+			if err := os.WriteFile(schemaFile, []byte(test.schema), 0600); err != nil {
+				t.Fatal(err)
+			}
+			schema, err := LoadOpenApiSchema(schemaFile)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := defaultBaseURL(schema); got != test.want {
+				t.Fatalf("got base url %q, want %q", got, test.want)
+			}
+			dir := t.TempDir()
+			if err := GenerateClient(schema, dir); err != nil {
+				t.Fatal(err)
+			}
+			client, err := os.ReadFile(filepath.Join(dir, "client.go"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			defaulted := strings.Contains(string(client), `cfg.BaseURL = "`+test.want+`"`)
+			if defaulted != (test.want != "") {
+				t.Errorf("the base url default of the generated client is %v, want %v", defaulted, test.want != "")
+			}
+		})
+	}
+}
@@ -0,0 +1,284 @@
+package openapi
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/iancoleman/strcase"
+)
+
+// Pagination types of paginated operations.
+const (
+	OffsetPagination = "offset"
+	PagePagination   = "page"
+	CursorPagination = "cursor"
+	LinkPagination   = "link"
+)
+
+// Pagination is the x-pagination vendor extension of an operation, the
+// parameters and response fields which are not set are detected with the
+// pagination heuristics.
+type Pagination struct {
+	// Type is offset, page, cursor or link (RFC 5988 Link headers).
+	Type        string `json:"type" yaml:"type"`
+	LimitParam  string `json:"limitParam" yaml:"limitParam"`
+	OffsetParam string `json:"offsetParam" yaml:"offsetParam"`
+	PageParam   string `json:"pageParam" yaml:"pageParam"`
+	CursorParam string `json:"cursorParam" yaml:"cursorParam"`
+	// NextCursor is the response field containing the cursor of the next
+	// page.
+	NextCursor string `json:"nextCursor" yaml:"nextCursor"`
+	// Items is the response field containing the items of a page, it is
+	// not needed when the response is an array.
+	Items string `json:"items" yaml:"items"`
+}
+
+// PaginationHeuristics contains the parameter and response field names
+// used to detect the pagination of GET operations without x-pagination.
+type PaginationHeuristics struct {
+	// Disabled restricts pagination to the operations with x-pagination.
+	Disabled         bool
+	LimitParams      []string
+	OffsetParams     []string
+	PageParams       []string
+	CursorParams     []string
+	NextCursorFields []string
+	ItemsFields      []string
+}
+
+// DefaultPaginationHeuristics returns the default pagination heuristics.
+func DefaultPaginationHeuristics() PaginationHeuristics {
+	return PaginationHeuristics{
+		LimitParams:      []string{"limit", "per_page", "perPage", "page_size", "pageSize"},
+		OffsetParams:     []string{"offset", "skip"},
+		PageParams:       []string{"page", "page_number", "pageNumber"},
+		CursorParams:     []string{"cursor", "after", "page_token", "pageToken", "next_token", "nextToken"},
+		NextCursorFields: []string{"next_cursor", "nextCursor", "next_page_token", "nextPageToken", "next_token", "nextToken"},
+		ItemsFields:      []string{"data", "items", "results"},
+	}
+}
+
+// OperationPagination contains the Go fields used by the pagination
+// iterators of an operation.
+type OperationPagination struct {
+	Type string
+	// ItemsField is the response field of the items, it is empty when the
+	// response is the list of items.
+	ItemsField string
+	// ItemType is empty if the items of the pages are unknown.
+	ItemType TypeName
+	Limit    *OperationParameter
+	Offset   *OperationParameter
+	Page     *OperationParameter
+	Cursor   *OperationParameter
+	// NextCursorField is the response field of the next page cursor.
+	NextCursorField string
+}
+
+// operationPagination returns the pagination of an operation from its
+// x-pagination extension or the pagination heuristics, nil is returned
+// for operations which are not paginated.
+func operationPagination(schema *OpenAPISchema, httpMethod string, operation Path) (*OperationPagination, error) {
+	heuristics := schema.Pagination
+	explicit := operation.XPagination
+	if explicit == nil {
+		if heuristics.Disabled || !strings.EqualFold(httpMethod, "get") {
+			return nil, nil
+		}
+		explicit = &Pagination{}
+	}
+	pagination, err := detectPagination(schema, operation, *explicit, heuristics)
+	if err != nil && operation.XPagination != nil {
+		return nil, fmt.Errorf("x-pagination: %w", err)
+	}
+	if err != nil {
+		return nil, nil
+	}
+	return pagination, nil
+}
+
+func detectPagination(schema *OpenAPISchema, operation Path, explicit Pagination, heuristics PaginationHeuristics) (*OperationPagination, error) {
+	response, ok := successResponse(schema, operation.Responses)
+	if !ok {
+		return nil, fmt.Errorf("one success response is required")
+	}
+	params := map[string]OperationParameter{}
+	for _, param := range operationParameters(schema, operation) {
+		if param.In == "query" {
+			params[param.Name] = param
+		}
+	}
+	pagination := &OperationPagination{}
+	definition := extractResponseDefinition(schema, response)
+	if definition != nil && definition.Type == "array" && definition.Items != nil {
+		pagination.ItemType = extractTypeName(schema, *definition.Items)
+	} else if definition != nil {
+		if name, ok := findProperty(*definition, explicit.Items, heuristics.ItemsFields, "array"); ok {
+			pagination.ItemsField = strcase.ToCamel(name)
+			pagination.ItemType = "interface{}"
+			if items := definition.Properties[name].Items; items != nil {
+				pagination.ItemType = extractTypeName(schema, *items)
+			}
+		} else if explicit.Items != "" {
+			return nil, fmt.Errorf("response field %s is not an array", explicit.Items)
+		}
+	}
+	var err error
+	pagination.Limit, err = findParameter(params, explicit.LimitParam, heuristics.LimitParams, "int")
+	if err != nil {
+		return nil, err
+	}
+	pagination.Offset, err = findParameter(params, explicit.OffsetParam, heuristics.OffsetParams, "int")
+	if err != nil {
+		return nil, err
+	}
+	pagination.Page, err = findParameter(params, explicit.PageParam, heuristics.PageParams, "int")
+	if err != nil {
+		return nil, err
+	}
+	pagination.Cursor, err = findParameter(params, explicit.CursorParam, heuristics.CursorParams, "string")
+	if err != nil {
+		return nil, err
+	}
+	if definition != nil {
+		if name, ok := findProperty(*definition, explicit.NextCursor, heuristics.NextCursorFields, "string"); ok {
+			pagination.NextCursorField = strcase.ToCamel(name)
+		} else if explicit.NextCursor != "" {
+			return nil, fmt.Errorf("response field %s is not a string", explicit.NextCursor)
+		}
+	}
+
+	pagination.Type = explicit.Type
+	if pagination.Type == "" {
+		switch {
+		case pagination.Cursor != nil && pagination.NextCursorField != "":
+			pagination.Type = CursorPagination
+		case hasResponseHeader(schema, response, "Link"):
+			pagination.Type = LinkPagination
+		case pagination.Offset != nil:
+			pagination.Type = OffsetPagination
+		case pagination.Page != nil:
+			pagination.Type = PagePagination
+		default:
+			return nil, fmt.Errorf("no pagination parameters found")
+		}
+	}
+	switch pagination.Type {
+	case OffsetPagination, PagePagination:
+		if pagination.ItemType == "" {
+			return nil, fmt.Errorf("%s pagination requires the items of the response", pagination.Type)
+		}
+		if pagination.Type == OffsetPagination && pagination.Offset == nil {
+			return nil, fmt.Errorf("offset pagination requires an integer offset parameter")
+		}
+		if pagination.Type == PagePagination && pagination.Page == nil {
+			return nil, fmt.Errorf("page pagination requires an integer page parameter")
+		}
+	case CursorPagination:
+		if pagination.Cursor == nil || pagination.NextCursorField == "" {
+			return nil, fmt.Errorf("cursor pagination requires a string cursor parameter and next cursor response field")
+		}
+	case LinkPagination:
+	default:
+		return nil, fmt.Errorf("unknown pagination type %s", pagination.Type)
+	}
+	return pagination, nil
+}
+
+// successResponse returns the success response of an operation if it has
+// exactly one success response with a schema.
+func successResponse(schema *OpenAPISchema, responses map[string]Property) (Property, bool) {
+	codes := []string{}
+	for code, response := range responses {
+		if isSuccessStatusCode(code) && extractResponseDefinition(schema, response) != nil {
+			codes = append(codes, code)
+		}
+	}
+	if len(codes) != 1 {
+		return Property{}, false
+	}
+	return responses[codes[0]], true
+}
+
+// findParameter returns the query parameter named name or the first one
+// matching the heuristic names, the parameter must have the Go type.
+func findParameter(params map[string]OperationParameter, name string, names []string, typ TypeName) (*OperationParameter, error) {
+	if name != "" {
+		param, ok := params[name]
+		if !ok {
+			return nil, fmt.Errorf("query parameter %s not found", name)
+		}
+		if strings.TrimPrefix(param.TypeName.String(), "*") != typ.String() {
+			return nil, fmt.Errorf("query parameter %s is not of type %s", name, typ)
+		}
+		return &param, nil
+	}
+	for _, name := range names {
+		param, ok := params[name]
+		if ok && strings.TrimPrefix(param.TypeName.String(), "*") == typ.String() {
+			return &param, nil
+		}
+	}
+	return nil, nil
+}
+
+// findProperty returns the property named name or the first one matching
+// the heuristic names, the property must have the schema type.
+func findProperty(definition Property, name string, names []string, typ SchemaType) (string, bool) {
+	if name != "" {
+		names = []string{name}
+	}
+	for _, name := range names {
+		if property, ok := definition.Properties[name]; ok && property.Type == typ {
+			return name, true
+		}
+	}
+	return "", false
+}
+
+// hasResponseHeader returns true if a response declares the header.
+func hasResponseHeader(schema *OpenAPISchema, response Property, header string) bool {
+	for response.Ref != "" {
+		resolved, ok := schema.RefPropertyMap[response.Ref]
+		if !ok {
+			return false
+		}
+		response = resolved
+	}
+	for name := range response.Headers {
+		if strings.EqualFold(name, header) {
+			return true
+		}
+	}
+	return false
+}
+
+// hasPagination returns true if any generated operation of the schema is
+// paginated.
+func hasPagination(schema *OpenAPISchema) bool {
+	for _, paths := range schema.ApiPathsMap {
+		for _, operations := range paths {
+			for httpMethod, operation := range operations {
+				if pagination, _ := operationPagination(schema, httpMethod, operation); pagination != nil {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// validatePagination returns the first invalid x-pagination extension of
+// the generated operations.
+func validatePagination(schema *OpenAPISchema) error {
+	for _, paths := range schema.ApiPathsMap {
+		for path, operations := range paths {
+			for httpMethod, operation := range operations {
+				if _, err := operationPagination(schema, httpMethod, operation); err != nil {
+					return fmt.Errorf("%s %s: %w", strings.ToUpper(httpMethod), path, err)
+				}
+			}
+		}
+	}
+	return nil
+}
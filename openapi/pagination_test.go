@@ -0,0 +1,141 @@
+package openapi
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// loadPaginationSchema loads the paths of a schema with the Pet schema of
+// testdata/pagination.yaml.
+func loadPaginationSchema(t *testing.T, paths string) *OpenAPISchema {
+	t.Helper()
+	schemaFile := filepath.Join(t.TempDir(), "schema.yaml")
+	schema := `
+openapi: 3.0.0
+info: {title: Test, version: 1.0.0}
+paths:
+` + paths + `
+components:
+  schemas:
+    Pet:
+      type: object
+      properties:
+        name: {type: string}
+`
+	if err := os.WriteFile(schemaFile, []byte(schema), 0600); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := LoadOpenApiSchema(schemaFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return loaded
+}
+
+func TestOperationPagination(t *testing.T) {
+	const pets = `{type: array, items: {$ref: "#/components/schemas/Pet"}}`
+	tests := []struct {
+		name      string
+		method    string
+		operation string
+		disabled  bool
+		// want is the pagination type, it is empty for operations which
+		// are not paginated.
+		want, items, err string
+	}{
+		{"offset", "get", `
+      parameters:
+        - {name: limit, in: query, schema: {type: integer}}
+        - {name: skip, in: query, schema: {type: integer}}
+      responses:
+        "200": {description: ok, content: {application/json: {schema: ` + pets + `}}}`, false, "offset", "", ""},
+		{"page", "get", `
+      parameters:
+        - {name: pageNumber, in: query, schema: {type: integer}}
+      responses:
+        "200": {description: ok, content: {application/json: {schema: {type: object, properties: {results: ` + pets + `}}}}}`, false, "page", "Results", ""},
+		{"cursor", "get", `
+      parameters:
+        - {name: after, in: query, schema: {type: string}}
+      responses:
+        "200": {description: ok, content: {application/json: {schema: {type: object, properties: {nextToken: {type: string}}}}}}`, false, "cursor", "", ""},
+		{"cursor without next cursor", "get", `
+      parameters:
+        - {name: after, in: query, schema: {type: string}}
+      responses:
+        "200": {description: ok, content: {application/json: {schema: ` + pets + `}}}`, false, "", "", ""},
+		{"link", "get", `
+      responses:
+        "200": {description: ok, headers: {link: {schema: {type: string}}}, content: {application/json: {schema: ` + pets + `}}}`, false, "link", "", ""},
+		{"string offset", "get", `
+      parameters:
+        - {name: offset, in: query, schema: {type: string}}
+      responses:
+        "200": {description: ok, content: {application/json: {schema: ` + pets + `}}}`, false, "", "", ""},
+		{"post", "post", `
+      parameters:
+        - {name: offset, in: query, schema: {type: integer}}
+      responses:
+        "200": {description: ok, content: {application/json: {schema: ` + pets + `}}}`, false, "", "", ""},
+		{"heuristics disabled", "get", `
+      parameters:
+        - {name: offset, in: query, schema: {type: integer}}
+      responses:
+        "200": {description: ok, content: {application/json: {schema: ` + pets + `}}}`, true, "", "", ""},
+		{"x-pagination", "post", `
+      x-pagination: {type: cursor, cursorParam: token, nextCursor: continuation, items: hits}
+      parameters:
+        - {name: token, in: query, schema: {type: string}}
+      responses:
+        "200": {description: ok, content: {application/json: {schema: {type: object, properties: {hits: ` + pets + `, continuation: {type: string}}}}}}`, true, "cursor", "Hits", ""},
+		{"x-pagination missing parameter", "get", `
+      x-pagination: {type: offset, offsetParam: start}
+      responses:
+        "200": {description: ok, content: {application/json: {schema: ` + pets + `}}}`, false, "", "", "x-pagination: query parameter start not found"},
+		{"x-pagination unknown type", "get", `
+      x-pagination: {type: token}
+      responses:
+        "200": {description: ok, content: {application/json: {schema: ` + pets + `}}}`, false, "", "", "x-pagination: unknown pagination type token"},
+		{"x-pagination without items", "get", `
+      x-pagination: {type: page}
+      parameters:
+        - {name: page, in: query, schema: {type: integer}}
+      responses:
+        "200": {description: ok, content: {application/json: {schema: {type: object, properties: {total: {type: integer}}}}}}`, false, "", "", "x-pagination: page pagination requires the items of the response"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			schema := loadPaginationSchema(t, "  /pets:\n    "+test.method+":\n      operationId: listPets\n      tags: [pets]"+test.operation)
+			schema.Pagination.Disabled = test.disabled
+			operation := schema.ApiPathsMap["pets"]["/pets"][test.method]
+			pagination, err := operationPagination(schema, test.method, operation)
+			if test.err != "" {
+				if err == nil || err.Error() != test.err {
+					t.Fatalf("got error %v, want %q", err, test.err)
+				}
+				if err := validatePagination(schema); err == nil || !strings.HasPrefix(err.Error(), strings.ToUpper(test.method)+" /pets: ") {
+					t.Errorf("got validation error %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if test.want == "" {
+				if pagination != nil {
+					t.Errorf("got %s pagination, want none", pagination.Type)
+				}
+				return
+			}
+			if pagination == nil || pagination.Type != test.want || pagination.ItemsField != test.items {
+				t.Fatalf("got pagination %+v, want %s with items %q", pagination, test.want, test.items)
+			}
+		})
+	}
+}
+
+func TestGeneratedPagination(t *testing.T) {
+	testClient(t, "testdata/pagination.yaml", "testdata/pagination_client_test.go")
+}
@@ -0,0 +1,256 @@
+package openapi
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/iancoleman/strcase"
+)
+
+var (
+	pathPlaceholderRegexp = regexp.MustCompile(`{([^{}]+)}`)
+
+	// reservedVariableNames contains Go keywords and identifiers used by
+	// the generated operation methods that parameter names must not shadow.
+	reservedVariableNames = map[string]bool{
+		"break": true, "case": true, "chan": true, "const": true, "continue": true,
+		"default": true, "defer": true, "else": true, "fallthrough": true, "for": true,
+		"func": true, "go": true, "goto": true, "if": true, "import": true,
+		"interface": true, "map": true, "package": true, "range": true, "return": true,
+		"select": true, "struct": true, "switch": true, "type": true, "var": true,
+		"ctx": true, "input": true, "s": true, "path": true, "response": true,
+		"requestBody": true, "accepts": true, "consumes": true, "err": true,
+		"fmt": true, "url": true, "http": true, "params": true,
+		"security": true, "form": true, "requestParams": true, "errorModel": true,
+		"page": true, "pageParams": true, "it": true, "pages": true,
+	}
+)
+
+// Property returns the schema of the parameter, swagger 2.0 non body
+// parameters declare their type on the parameter itself.
+func (p PathParameter) Property() Property {
+	if p.Schema.Type != "" || p.Schema.Ref != "" || p.Type == "" {
+		return p.Schema
+	}
+	return Property{
+		Type:   p.Type,
+		Format: p.Format,
+		Items:  p.Items,
+	}
+}
+
+// pathPlaceholders returns the names of the {placeholders} in a path
+// in the order they appear.
+func pathPlaceholders(path string) []string {
+	names := []string{}
+	for _, match := range pathPlaceholderRegexp.FindAllStringSubmatch(path, -1) {
+		names = append(names, match[1])
+	}
+	return names
+}
+
+// pathParameters returns the path parameters of an operation ordered by
+// their position in the path.
+func pathParameters(path string, params []PathParameter) []PathParameter {
+	pathParams := []PathParameter{}
+	for _, name := range pathPlaceholders(path) {
+		for _, param := range params {
+			if param.In == "path" && param.Name == name {
+				pathParams = append(pathParams, param)
+				break
+			}
+		}
+	}
+	return pathParams
+}
+
+// validatePathParameters returns an error if a path placeholder does not
+// have a matching path parameter.
+func validatePathParameters(path string, params []PathParameter) error {
+	pathParams := pathParameters(path, params)
+	placeholders := pathPlaceholders(path)
+	if len(pathParams) == len(placeholders) {
+		return nil
+	}
+	for _, name := range placeholders {
+		found := false
+		for _, param := range pathParams {
+			if param.Name == name {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return fmt.Errorf("path placeholder {%s} has no matching path parameter", name)
+		}
+	}
+	return nil
+}
+
+// pathFormat converts a templated path (e.g /pet/{petId}) into a fmt
+// format string (e.g /pet/%s).
+func pathFormat(path string) string {
+	path = strings.ReplaceAll(path, "%", "%%")
+	return pathPlaceholderRegexp.ReplaceAllString(path, "%s")
+}
+
+// toVariableName converts a parameter name into a valid Go variable name
+// that does not clash with keywords or the generated method variables.
+func toVariableName(name string) string {
+	name = strcase.ToLowerCamel(name)
+	if name == "" || reservedVariableNames[name] || (name[0] >= '0' && name[0] <= '9') {
+		return "param" + strcase.ToCamel(name)
+	}
+	return name
+}
+
+// OperationParameter is a query, header or cookie parameter of an
+// operation together with the details of its generated Go field.
+type OperationParameter struct {
+	PathParameter
+	FieldName        string
+	TypeName         TypeName
+	CollectionFormat string
+	IsPointer        bool
+	// IsFile is true for formData file parameters, IsFileList is true for
+	// formData parameters which are a list of files.
+	IsFile     bool
+	IsFileList bool
+	// ZeroValue is the value a required parameter is compared against
+	// to detect that it has not been set, it is empty if the parameter
+	// type has no unset state.
+	ZeroValue string
+}
+
+// bodyParameter returns the body parameter of an operation if it has one.
+func bodyParameter(params []PathParameter) *PathParameter {
+	for _, param := range params {
+		if param.In == "body" {
+			return &param
+		}
+	}
+	return nil
+}
+
+// operationParameters returns the query, header and cookie parameters
+// of an operation.
+func operationParameters(schema *OpenAPISchema, operation Path) []OperationParameter {
+	operationParams := []OperationParameter{}
+	for _, param := range operation.Parameters {
+		if param.In != "query" && param.In != "header" && param.In != "cookie" {
+			continue
+		}
+		// these headers are controlled by the client and must be ignored
+		// when declared as parameters, the Authorization header only when
+		// it is set by a security scheme of the operation.
+		if param.In == "header" {
+			switch strings.ToLower(param.Name) {
+			case "accept", "content-type":
+				continue
+			case "authorization":
+				if setsAuthorizationHeader(schema, operation.Security) {
+					continue
+				}
+			}
+		}
+		operationParams = append(operationParams, newOperationParameter(schema, param))
+	}
+	return operationParams
+}
+
+// formParameters returns the formData parameters of an operation.
+func formParameters(schema *OpenAPISchema, params []PathParameter) []OperationParameter {
+	formParams := []OperationParameter{}
+	for _, param := range params {
+		if param.In == "formData" {
+			formParams = append(formParams, newOperationParameter(schema, param))
+		}
+	}
+	return formParams
+}
+
+func newOperationParameter(schema *OpenAPISchema, param PathParameter) OperationParameter {
+	operationParam := OperationParameter{
+		PathParameter:    param,
+		FieldName:        strcase.ToCamel(param.Name),
+		CollectionFormat: param.collectionFormat(schema.IsOpenAPIV3()),
+	}
+	var typeName TypeName
+	switch {
+	case param.Type == "file":
+		typeName = "*FormFile"
+		operationParam.IsFile = true
+	case param.Type == "array" && param.Items != nil && param.Items.Type == "file":
+		typeName = "[]*FormFile"
+		operationParam.IsFileList = true
+	default:
+		typeName = extractTypeName(schema, param.Property())
+	}
+	operationParam.TypeName = typeName
+	nullable := typeName.IsNullable() || typeName == "interface{}"
+	switch {
+	case !param.Required && !nullable:
+		operationParam.TypeName = "*" + typeName
+		operationParam.IsPointer = true
+	case param.Required && nullable:
+		operationParam.ZeroValue = "nil"
+	case param.Required && typeName == "string":
+		operationParam.ZeroValue = `""`
+	}
+	return operationParam
+}
+
+// isMultipart returns true if an operation with form parameters must be
+// sent as multipart/form-data instead of an url encoded form.
+func isMultipart(consumes []string, formParams []OperationParameter) bool {
+	for _, param := range formParams {
+		if param.IsFile || param.IsFileList {
+			return true
+		}
+	}
+	for _, contentType := range consumes {
+		if strings.HasPrefix(strings.ToLower(contentType), "multipart/form-data") {
+			return true
+		}
+	}
+	return false
+}
+
+// hasFormParameters returns true if any operation of the schema has
+// formData parameters.
+func hasFormParameters(schema *OpenAPISchema) bool {
+	for _, pathItem := range schema.Paths {
+		for _, pathInfo := range pathItem {
+			for _, param := range pathInfo.Parameters {
+				if param.In == "formData" {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// collectionFormat returns the swagger 2.0 collection format (csv, ssv,
+// tsv, pipes or multi) used for serializing array parameter values.
+func (p PathParameter) collectionFormat(isOpenAPIV3 bool) string {
+	if !isOpenAPIV3 {
+		if p.CollectionFormat == "" {
+			return "csv"
+		}
+		return p.CollectionFormat
+	}
+	switch p.Style {
+	case "spaceDelimited":
+		return "ssv"
+	case "pipeDelimited":
+		return "pipes"
+	case "", "form":
+		// form style parameters are exploded by default.
+		if (p.In == "query" || p.In == "formData") && (p.Explode == nil || *p.Explode) {
+			return "multi"
+		}
+	}
+	return "csv"
+}
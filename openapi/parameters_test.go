@@ -0,0 +1,95 @@
+package openapi
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadOpenApiSchemaMissingPathParameter(t *testing.T) {
+	schemaFile := filepath.Join(t.TempDir(), "schema.yaml")
+	schema := `
+openapi: 3.0.0
+info: {title: Test, version: 1.0.0}
+paths:
+  /pets/{petId}/photos/{photoId}:
+    get:
+      operationId: getPhoto
+      parameters:
+        - {name: petId, in: path, required: true, schema: {type: string}}
+      responses:
+        "204": {description: ok}
+`
+	if err := os.WriteFile(schemaFile, []byte(schema), 0600); err != nil {
+		t.Fatal(err)
+	}
+	_, err := LoadOpenApiSchema(schemaFile)
+	want := "GET /pets/{petId}/photos/{photoId}: path placeholder {photoId} has no matching path parameter"
+	if err == nil || !strings.Contains(err.Error(), want) {
+		t.Fatalf("got error %v, want %q", err, want)
+	}
+}
+
+func TestToVariableName(t *testing.T) {
+	tests := map[string]string{
+		"petId":         "petId",
+		"photo_id":      "photoId",
+		"type":          "paramType",
+		"path":          "paramPath",
+		"2fa":           "param2Fa",
+		"page":          "paramPage",
+		"it":            "paramIt",
+		"security":      "paramSecurity",
+		"form":          "paramForm",
+		"requestParams": "paramRequestParams",
+		"errorModel":    "paramErrorModel",
+	}
+	for name, want := range tests {
+		if got := toVariableName(name); got != want {
+			t.Errorf("toVariableName(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
+
+func TestGeneratedPathParameters(t *testing.T) {
+	testClient(t, "testdata/path_parameters.yaml", "testdata/path_parameters_client_test.go")
+}
+
+func TestGeneratedForms(t *testing.T) {
+	testClient(t, "testdata/forms.yaml", "testdata/forms_client_test.go")
+}
+
+func TestOperationParametersAuthorization(t *testing.T) {
+	schema := &OpenAPISchema{
+		RefMap:         map[string]string{},
+		RefPropertyMap: map[string]Property{},
+		SecurityDefinitions: map[string]SecurityDefinition{
+			"bearer":     {Type: "http", Flow: "bearer"},
+			"apiKey":     {Type: "apiKey", In: "header", Name: "X-API-Key"},
+			"authHeader": {Type: "apiKey", In: "header", Name: "authorization"},
+		},
+	}
+	params := []PathParameter{
+		{In: "header", Name: "Authorization", Schema: Property{Type: "string"}},
+		{In: "header", Name: "Accept", Schema: Property{Type: "string"}},
+	}
+	tests := []struct {
+		name     string
+		security []map[string][]string
+		want     bool
+	}{
+		{"no security", nil, true},
+		{"bearer", []map[string][]string{{"bearer": {}}}, false},
+		{"other api key header", []map[string][]string{{"apiKey": {}}}, true},
+		{"authorization api key", []map[string][]string{{"authHeader": {}}}, false},
+		{"unknown scheme", []map[string][]string{{"unknown": {}}}, true},
+	}
+	for _, test := range tests {
+		operationParams := operationParameters(schema, Path{Parameters: params, Security: test.security})
+		got := len(operationParams) == 1 && operationParams[0].Name == "Authorization"
+		if got != test.want || len(operationParams) > 1 {
+			t.Errorf("%s: got parameters %v, want the Authorization parameter %v", test.name, operationParams, test.want)
+		}
+	}
+}
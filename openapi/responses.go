@@ -0,0 +1,120 @@
+package openapi
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/iancoleman/strcase"
+)
+
+// ErrorResponse is a non success response declared by an operation.
+type ErrorResponse struct {
+	// Code is the response status code, a status code range (e.g 4XX)
+	// or default.
+	Code string
+	// Condition is the Go expression matching the response statusCode,
+	// it is empty for the default response.
+	Condition string
+	TypeName  TypeName
+	// Definition is the Go type definition of TypeName when the
+	// response schema is declared inline.
+	Definition string
+}
+
+// isSuccessStatusCode returns true for 2xx status codes and the 2XX range.
+func isSuccessStatusCode(code string) bool {
+	return strings.HasPrefix(code, "2")
+}
+
+// errorResponses returns the non success responses of an operation which
+// declare a schema, ordered by status code with ranges and the default
+// response last.
+func errorResponses(schema *OpenAPISchema, methodName string, responses map[string]Property) []ErrorResponse {
+	errResponses := []ErrorResponse{}
+	for code, response := range responses {
+		if isSuccessStatusCode(code) {
+			continue
+		}
+		typeName, definition := responseTypeName(schema, response)
+		if typeName == "" && definition == "" {
+			continue
+		}
+		errResponse := ErrorResponse{
+			Code:       code,
+			TypeName:   typeName,
+			Definition: definition,
+		}
+		if definition != "" {
+			errResponse.TypeName = TypeName(methodName + "Error" + strcase.ToCamel(code))
+		}
+		if statusCode, err := strconv.Atoi(code); err == nil {
+			errResponse.Condition = fmt.Sprintf("statusCode == %d", statusCode)
+		} else if len(code) == 3 && strings.HasSuffix(strings.ToUpper(code), "XX") {
+			class, _ := strconv.Atoi(code[:1])
+			errResponse.Condition = fmt.Sprintf("statusCode >= %d && statusCode < %d", class*100, (class+1)*100)
+		} else if code != "default" {
+			continue
+		}
+		errResponses = append(errResponses, errResponse)
+	}
+	sort.Slice(errResponses, func(i, j int) bool {
+		return responseOrder(errResponses[i].Code) < responseOrder(errResponses[j].Code)
+	})
+	return errResponses
+}
+
+func responseOrder(code string) string {
+	if code == "default" {
+		return "2" + code
+	}
+	if _, err := strconv.Atoi(code); err != nil {
+		return "1" + code
+	}
+	return "0" + code
+}
+
+// responseTypeName returns the Go type name of a response schema, if the
+// schema is an inline object the struct definition is returned instead.
+func responseTypeName(schema *OpenAPISchema, response Property) (TypeName, string) {
+	name := ""
+	for response.Ref != "" {
+		resolved, ok := schema.RefPropertyMap[response.Ref]
+		if !ok {
+			return "", ""
+		}
+		name = schema.RefMap[response.Ref]
+		response = resolved
+	}
+	if response.Schema == nil {
+		return "", ""
+	}
+	property := *response.Schema
+	if property.Ref != "" || (property.Type != "object" && len(property.Properties) == 0) {
+		return extractTypeName(schema, property), ""
+	}
+	// inline object schemas of named responses are generated as types.
+	if name != "" && property.Type == "object" {
+		return TypeName(strcase.ToCamel(name)), ""
+	}
+	return "", structDefinition(schema, property)
+}
+
+// structDefinition returns the Go struct definition of an object schema.
+func structDefinition(schema *OpenAPISchema, property Property) string {
+	names := []string{}
+	for name := range property.Properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	definition := "struct { \n"
+	for _, name := range names {
+		fieldType := extractTypeName(schema, property.Properties[name])
+		if !property.IsRequired(name) && !fieldType.IsBuiltIn() && !fieldType.IsNullable() {
+			fieldType = "*" + fieldType
+		}
+		definition += fmt.Sprintf("%s %s `json:\"%s,omitempty\" %s` \n", strcase.ToCamel(name), fieldType, name, xmlTag(schema, name, property.Properties[name]))
+	}
+	return definition + "}"
+}
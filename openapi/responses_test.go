@@ -0,0 +1,38 @@
+package openapi
+
+import "testing"
+
+func TestErrorResponses(t *testing.T) {
+	schema := &OpenAPISchema{RefMap: map[string]string{}, RefPropertyMap: map[string]Property{}}
+	errorSchema := &Property{Type: "string"}
+	responses := map[string]Property{
+		"200":     {Schema: errorSchema},
+		"default": {Schema: errorSchema},
+		"5XX":     {Schema: errorSchema},
+		"404":     {Schema: errorSchema},
+		"400":     {Schema: errorSchema},
+		"409":     {Description: "no schema"},
+	}
+	want := []struct {
+		code      string
+		condition string
+	}{
+		{"400", "statusCode == 400"},
+		{"404", "statusCode == 404"},
+		{"5XX", "statusCode >= 500 && statusCode < 600"},
+		{"default", ""},
+	}
+	got := errorResponses(schema, "GetPet", responses)
+	if len(got) != len(want) {
+		t.Fatalf("got %d error responses, want %d: %+v", len(got), len(want), got)
+	}
+	for i, response := range got {
+		if response.Code != want[i].code || response.Condition != want[i].condition || response.TypeName != "string" {
+			t.Errorf("got error response %+v, want %+v", response, want[i])
+		}
+	}
+}
+
+func TestGeneratedAPIErrors(t *testing.T) {
+	testClient(t, "testdata/errors.yaml", "testdata/errors_client_test.go")
+}
@@ -0,0 +1,124 @@
+package openapi
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/iancoleman/strcase"
+)
+
+// SecurityScheme is a security definition supported by the generated
+// clients.
+type SecurityScheme struct {
+	SecurityDefinition
+	// SchemeName is the name of the security definition in the schema.
+	SchemeName string
+	// FieldName is the name of the credential field in the generated
+	// client configuration.
+	FieldName string
+	// Kind is one of apiKey, basic, bearer or oauth2.
+	Kind string
+}
+
+// securityKind returns the kind of credential used by a security
+// definition or an empty string if the definition is not supported.
+func securityKind(definition SecurityDefinition) string {
+	switch definition.Type {
+	case "apiKey":
+		if definition.Name == "" {
+			return ""
+		}
+		switch definition.In {
+		case "header", "query", "cookie":
+			return "apiKey"
+		}
+	case "basic":
+		return "basic"
+	case "http":
+		switch definition.Flow {
+		case "basic":
+			return "basic"
+		case "bearer":
+			return "bearer"
+		}
+	case "oauth2":
+		return "oauth2"
+	case "openIdConnect":
+		return "bearer"
+	}
+	return ""
+}
+
+// securitySchemes returns the supported security definitions of a schema
+// sorted by name.
+func securitySchemes(schema *OpenAPISchema) []SecurityScheme {
+	schemes := []SecurityScheme{}
+	for name, definition := range schema.SecurityDefinitions {
+		kind := securityKind(definition)
+		if kind == "" {
+			continue
+		}
+		schemes = append(schemes, SecurityScheme{
+			SecurityDefinition: definition,
+			SchemeName:         name,
+			FieldName:          strcase.ToCamel(name),
+			Kind:               kind,
+		})
+	}
+	sort.Slice(schemes, func(i, j int) bool {
+		return schemes[i].SchemeName < schemes[j].SchemeName
+	})
+	return schemes
+}
+
+// setsAuthorizationHeader returns true if a security scheme of the
+// security requirements sets the Authorization header.
+func setsAuthorizationHeader(schema *OpenAPISchema, security []map[string][]string) bool {
+	for _, requirement := range security {
+		for name := range requirement {
+			definition, ok := schema.SecurityDefinitions[name]
+			if !ok {
+				continue
+			}
+			switch securityKind(definition) {
+			case "basic", "bearer", "oauth2":
+				return true
+			case "apiKey":
+				if definition.In == "header" && strings.EqualFold(definition.Name, "Authorization") {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// CredentialType returns the Go type of the credential field.
+func (s SecurityScheme) CredentialType() string {
+	switch s.Kind {
+	case "apiKey":
+		return "APIKey"
+	case "basic":
+		return "*BasicAuth"
+	}
+	return "TokenSource"
+}
+
+// ZeroValue returns the value of the credential field when the credential
+// has not been configured.
+func (s SecurityScheme) ZeroValue() string {
+	if s.Kind == "apiKey" {
+		return `""`
+	}
+	return "nil"
+}
+
+// TokenURLExpression returns the Go expression of the oauth2 token url,
+// relative urls are resolved against the client base url.
+func (s SecurityScheme) TokenURLExpression() string {
+	if strings.Contains(s.TokenURL, "://") {
+		return strconv.Quote(s.TokenURL)
+	}
+	return "cfg.BaseURL + " + strconv.Quote("/"+strings.TrimPrefix(s.TokenURL, "/"))
+}
@@ -0,0 +1,28 @@
+package openapi
+
+import "testing"
+
+func TestSecurityKind(t *testing.T) {
+	tests := []struct {
+		definition SecurityDefinition
+		want       string
+	}{
+		{SecurityDefinition{Type: "apiKey", In: "header", Name: "X-API-Key"}, "apiKey"},
+		{SecurityDefinition{Type: "apiKey", In: "header"}, ""},
+		{SecurityDefinition{Type: "basic"}, "basic"},
+		{SecurityDefinition{Type: "http", Flow: "basic"}, "basic"},
+		{SecurityDefinition{Type: "http", Flow: "bearer"}, "bearer"},
+		{SecurityDefinition{Type: "http", Flow: "digest"}, ""},
+		{SecurityDefinition{Type: "oauth2", Flow: "application"}, "oauth2"},
+		{SecurityDefinition{Type: "openIdConnect"}, "bearer"},
+	}
+	for _, test := range tests {
+		if got := securityKind(test.definition); got != test.want {
+			t.Errorf("securityKind(%+v) = %q, want %q", test.definition, got, test.want)
+		}
+	}
+}
+
+func TestGeneratedSecurity(t *testing.T) {
+	testClient(t, "testdata/security.yaml", "testdata/security_client_test.go")
+}
@@ -0,0 +1,227 @@
+package client
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+type codecRequest struct {
+	contentType string
+	accept      string
+	body        string
+}
+
+// codecServer records the requests and responds with the content type and
+// body of the responses, the last one is repeated.
+type codecServer struct {
+	mu        sync.Mutex
+	requests  []codecRequest
+	responses []codecResponse
+}
+
+type codecResponse struct {
+	statusCode  int
+	contentType string
+	body        string
+}
+
+func newCodecServer(t *testing.T, config ClientConfiguration, responses ...codecResponse) (*codecServer, *APIClient) {
+	s := &codecServer{responses: responses}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		s.mu.Lock()
+		s.requests = append(s.requests, codecRequest{r.Header.Get("Content-Type"), r.Header.Get("Accept"), string(body)})
+		response := s.responses[len(s.responses)-1]
+		if len(s.requests) <= len(s.responses) {
+			response = s.responses[len(s.requests)-1]
+		}
+		s.mu.Unlock()
+		if response.contentType != "" {
+			w.Header().Set("Content-Type", response.contentType)
+		}
+		if response.statusCode == 0 {
+			response.statusCode = http.StatusOK
+		}
+		w.WriteHeader(response.statusCode)
+		io.WriteString(w, response.body)
+	}))
+	t.Cleanup(server.Close)
+	config.BaseURL = server.URL
+	return s, NewAPIClient(config)
+}
+
+func (s *codecServer) requested() []codecRequest {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]codecRequest{}, s.requests...)
+}
+
+const petXML = `<pet id="1"><name>Rex</name><tags><tag>a</tag><tag>b</tag></tags></pet>`
+
+func TestJSONIsPreferred(t *testing.T) {
+	server, client := newCodecServer(t, ClientConfiguration{}, codecResponse{contentType: "application/xml; charset=utf-8", body: petXML})
+	pet, err := client.Pets.CreatePet(context.Background(), Pet{Id: 1, Name: "Rex"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	request := server.requested()[0]
+	if request.contentType != "application/json" || request.body != `{"id":1,"name":"Rex"}` {
+		t.Errorf("got request %+v, want a json body", request)
+	}
+	// the response is decoded by its content type.
+	if pet.Id != 1 || pet.Name != "Rex" || !reflect.DeepEqual(pet.Tags, []string{"a", "b"}) {
+		t.Errorf("got pet %+v", pet)
+	}
+}
+
+func TestXMLBody(t *testing.T) {
+	server, client := newCodecServer(t, ClientConfiguration{}, codecResponse{contentType: "application/xml", body: petXML})
+	if _, err := client.Pets.ReplacePet(context.Background(), Pet{Id: 1, Name: "Rex", Tags: []string{"a", "b"}}); err != nil {
+		t.Fatal(err)
+	}
+	request := server.requested()[0]
+	if request.contentType != "application/xml" || request.body != petXML {
+		t.Errorf("got request %+v, want the body %s", request, petXML)
+	}
+}
+
+func TestStructuredSyntaxSuffix(t *testing.T) {
+	_, client := newCodecServer(t, ClientConfiguration{}, codecResponse{contentType: "application/vnd.pet+json", body: `{"id": 2, "name": "Fido"}`})
+	pet, err := client.Pets.CreatePet(context.Background(), Pet{Name: "Fido"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pet.Id != 2 || pet.Name != "Fido" {
+		t.Errorf("got pet %+v", pet)
+	}
+}
+
+func TestTextBody(t *testing.T) {
+	server, client := newCodecServer(t, ClientConfiguration{}, codecResponse{contentType: "text/plain; charset=utf-8", body: "stored: hello"})
+	note, err := client.Pets.CreateNote(context.Background(), "hello")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *note != "stored: hello" {
+		t.Errorf("got note %q", *note)
+	}
+	request := server.requested()[0]
+	if request.contentType != "text/plain" || request.body != "hello" {
+		t.Errorf("got request %+v", request)
+	}
+}
+
+func TestBinaryBody(t *testing.T) {
+	server, client := newCodecServer(t, ClientConfiguration{}, codecResponse{statusCode: http.StatusNoContent})
+	if _, err := client.Pets.UploadData(context.Background(), bytes.NewReader([]byte{0, 1, 2})); err != nil {
+		t.Fatal(err)
+	}
+	request := server.requested()[0]
+	if request.contentType != "application/octet-stream" || request.body != "\x00\x01\x02" {
+		t.Errorf("got request %+v", request)
+	}
+}
+
+func TestBinaryResponse(t *testing.T) {
+	_, client := newCodecServer(t, ClientConfiguration{}, codecResponse{contentType: "application/octet-stream", body: "\x00data"})
+	data, err := client.Pets.DownloadData(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	contents, err := io.ReadAll(*data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(contents) != "\x00data" {
+		t.Errorf("got data %q", contents)
+	}
+}
+
+// yamlCodec encodes the name of pets as yaml.
+type yamlCodec struct{}
+
+func (yamlCodec) Encode(v interface{}) (io.Reader, error) {
+	pet, ok := v.(Pet)
+	if !ok {
+		return nil, fmt.Errorf("can not encode %T", v)
+	}
+	return strings.NewReader("name: " + pet.Name + "\n"), nil
+}
+
+func (yamlCodec) Decode(r io.Reader, v interface{}) error {
+	pet, ok := v.(*ReplacePetYAMLApiResponse)
+	if !ok {
+		return fmt.Errorf("can not decode %T", v)
+	}
+	line, err := bufio.NewReader(r).ReadString('\n')
+	if err != nil {
+		return err
+	}
+	pet.Name = strings.TrimSpace(strings.TrimPrefix(line, "name:"))
+	return nil
+}
+
+func TestCustomCodec(t *testing.T) {
+	server, client := newCodecServer(t, ClientConfiguration{Codecs: map[string]Codec{"Application/YAML": yamlCodec{}}}, codecResponse{contentType: "application/yaml", body: "name: Fido\n"})
+	pet, err := client.Pets.ReplacePetYAML(context.Background(), Pet{Name: "Rex"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pet.Name != "Fido" {
+		t.Errorf("got pet %+v", pet)
+	}
+	request := server.requested()[0]
+	if request.contentType != "application/yaml" || request.body != "name: Rex\n" {
+		t.Errorf("got request %+v", request)
+	}
+}
+
+func TestMissingCodec(t *testing.T) {
+	server, client := newCodecServer(t, ClientConfiguration{}, codecResponse{})
+	_, err := client.Pets.ReplacePetYAML(context.Background(), Pet{Name: "Rex"})
+	if err == nil || !strings.Contains(err.Error(), "no codec for the content types application/yaml") {
+		t.Errorf("got error %v, want the missing codec", err)
+	}
+	if len(server.requested()) != 0 {
+		t.Error("the request was sent without a codec")
+	}
+}
+
+// streamReader is not one of the readers for which http.NewRequest can
+// replay the body.
+type streamReader struct {
+	io.Reader
+}
+
+func TestBinaryBodyRetry(t *testing.T) {
+	retry := &RetryPolicy{MaxAttempts: 2, InitialBackoff: time.Millisecond}
+	unavailable := codecResponse{statusCode: http.StatusServiceUnavailable}
+	server, client := newCodecServer(t, ClientConfiguration{Retry: retry}, unavailable, codecResponse{statusCode: http.StatusNoContent})
+	if _, err := client.Pets.UploadData(context.Background(), bytes.NewReader([]byte("data"))); err != nil {
+		t.Fatal(err)
+	}
+	if requests := server.requested(); len(requests) != 2 || requests[1].body != "data" {
+		t.Errorf("got requests %+v, want the body to be sent again", requests)
+	}
+
+	server, client = newCodecServer(t, ClientConfiguration{Retry: retry}, unavailable, codecResponse{statusCode: http.StatusNoContent})
+	_, err := client.Pets.UploadData(context.Background(), streamReader{strings.NewReader("data")})
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("got error %v, want a 503 api error", err)
+	}
+	if requests := server.requested(); len(requests) != 1 {
+		t.Errorf("got %d requests, want streamed bodies not to be retried", len(requests))
+	}
+}
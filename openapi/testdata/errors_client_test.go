@@ -0,0 +1,110 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// errorServer responds with the status code and body of the requested
+// path.
+func errorServer(t *testing.T, statusCode int, contentType, body string) *APIClient {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if contentType != "" {
+			w.Header().Set("Content-Type", contentType)
+		}
+		w.Header().Set("X-Request-Id", "42")
+		w.WriteHeader(statusCode)
+		w.Write([]byte(body))
+	}))
+	t.Cleanup(server.Close)
+	return NewAPIClient(ClientConfiguration{BaseURL: server.URL})
+}
+
+func TestAPIErrorModels(t *testing.T) {
+	tests := []struct {
+		name       string
+		statusCode int
+		body       string
+		check      func(t *testing.T, model interface{})
+	}{
+		{"status code", http.StatusNotFound, `{"code":7,"message":"no such pet"}`, func(t *testing.T, model interface{}) {
+			e, ok := model.(*Error)
+			if !ok || e.Code != 7 || e.Message != "no such pet" {
+				t.Errorf("got model %#v, want *Error", model)
+			}
+		}},
+		{"status code range", http.StatusConflict, `{"reason":"taken"}`, func(t *testing.T, model interface{}) {
+			e, ok := model.(*GetPetError4XX)
+			if !ok || e.Reason != "taken" {
+				t.Errorf("got model %#v, want *GetPetError4XX", model)
+			}
+		}},
+		{"default", http.StatusInternalServerError, `{"title":"boom","status":500}`, func(t *testing.T, model interface{}) {
+			e, ok := model.(*Problem)
+			if !ok || e.Title != "boom" || e.Status != 500 {
+				t.Errorf("got model %#v, want *Problem", model)
+			}
+		}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			client := errorServer(t, test.statusCode, "application/json", test.body)
+			pet, err := client.Pets.GetPet(context.Background(), "1")
+			if pet != nil {
+				t.Errorf("got pet %+v, want nil", pet)
+			}
+			var apiErr *APIError
+			if !errors.As(err, &apiErr) {
+				t.Fatalf("got error %v, want an *APIError", err)
+			}
+			if apiErr.StatusCode != test.statusCode || string(apiErr.Body) != test.body || apiErr.Header.Get("X-Request-Id") != "42" {
+				t.Errorf("got api error %+v", apiErr)
+			}
+			test.check(t, apiErr.Model)
+		})
+	}
+}
+
+func TestAPIErrorWithoutModel(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		message     string
+	}{
+		{"invalid body", "text/html", "<h1>Bad Gateway</h1>", "api error: 502 Bad Gateway: <h1>Bad Gateway</h1>"},
+		{"empty body", "", "", "api error: 502 Bad Gateway"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			client := errorServer(t, http.StatusBadGateway, test.contentType, test.body)
+			_, err := client.Pets.GetPet(context.Background(), "1")
+			var apiErr *APIError
+			if !errors.As(err, &apiErr) {
+				t.Fatalf("got error %v, want an *APIError", err)
+			}
+			if apiErr.Model != nil {
+				t.Errorf("got model %#v, want nil", apiErr.Model)
+			}
+			if err.Error() != test.message {
+				t.Errorf("got message %q, want %q", err.Error(), test.message)
+			}
+		})
+	}
+}
+
+func TestAPIErrorUndeclared(t *testing.T) {
+	client := errorServer(t, http.StatusServiceUnavailable, "application/json", `{"message":"down"}`)
+	_, err := client.Pets.Health(context.Background())
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("got error %v, want an *APIError", err)
+	}
+	if apiErr.Model != nil || !strings.Contains(err.Error(), "503") {
+		t.Errorf("got api error %+v", apiErr)
+	}
+}
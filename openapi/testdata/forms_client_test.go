@@ -0,0 +1,153 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"testing/iotest"
+	"time"
+)
+
+// formServer decodes the form of the requests, the first bytes of the
+// photo file are signaled on received before the rest is read.
+func formServer(t *testing.T, received chan<- struct{}) *APIClient {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		form := Form{ContentType: r.Header.Get("Content-Type"), Values: map[string][]string{}}
+		if !strings.HasPrefix(form.ContentType, "multipart/form-data") {
+			r.ParseForm()
+			form.Values = r.PostForm
+			json.NewEncoder(w).Encode(form)
+			return
+		}
+		reader, err := r.MultipartReader()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		for {
+			part, err := reader.NextPart()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			if part.FileName() == "" {
+				value, _ := io.ReadAll(part)
+				form.Values[part.FormName()] = append(form.Values[part.FormName()], string(value))
+				continue
+			}
+			var contents []byte
+			if part.FormName() == "photo" && received != nil {
+				first := make([]byte, 5)
+				io.ReadFull(part, first)
+				received <- struct{}{}
+				contents = first
+			}
+			rest, _ := io.ReadAll(part)
+			contents = append(contents, rest...)
+			form.Files = append(form.Files, part.FormName()+"="+part.FileName()+";"+part.Header.Get("Content-Type")+";"+string(contents))
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(form)
+	}))
+	t.Cleanup(server.Close)
+	return NewAPIClient(ClientConfiguration{BaseURL: server.URL})
+}
+
+func TestMultipartForm(t *testing.T) {
+	client := formServer(t, nil)
+	caption, rank := "cute", 2
+	form, err := client.Pets.UploadPhotos(context.Background(), "1", &UploadPhotosForm{
+		Caption: &caption,
+		Rank:    &rank,
+		Photo:   &FormFile{Reader: strings.NewReader("photo"), Filename: `my "cat".png`, ContentType: "image/png"},
+		Extras: []*FormFile{
+			{Reader: strings.NewReader("a"), Filename: "a.txt"},
+			{Reader: strings.NewReader("b"), Filename: "b.txt"},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(form.ContentType, "multipart/form-data; boundary=") {
+		t.Errorf("got content type %q", form.ContentType)
+	}
+	if strings.Join(form.Values["caption"], ",") != "cute" || strings.Join(form.Values["rank"], ",") != "2" {
+		t.Errorf("got values %v", form.Values)
+	}
+	want := []string{
+		"extras=a.txt;application/octet-stream;a",
+		"extras=b.txt;application/octet-stream;b",
+		`photo=my "cat".png;image/png;photo`,
+	}
+	if strings.Join(form.Files, "\n") != strings.Join(want, "\n") {
+		t.Errorf("got files %q, want %q", form.Files, want)
+	}
+}
+
+func TestMultipartFormIsStreamed(t *testing.T) {
+	received := make(chan struct{})
+	client := formServer(t, received)
+	photoReader, photoWriter := io.Pipe()
+	go func() {
+		photoWriter.Write([]byte("first"))
+		// the rest of the photo is only written once the server received
+		// the beginning, a buffered body would never be sent.
+		select {
+		case <-received:
+			photoWriter.Write([]byte(" second"))
+			photoWriter.Close()
+		case <-time.After(5 * time.Second):
+			photoWriter.CloseWithError(errors.New("the photo was not streamed"))
+		}
+	}()
+	form, err := client.Pets.UploadPhotos(context.Background(), "1", &UploadPhotosForm{
+		Photo: &FormFile{Reader: photoReader, Filename: "photo.png"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "photo=photo.png;application/octet-stream;first second"; len(form.Files) != 1 || form.Files[0] != want {
+		t.Errorf("got files %q, want %q", form.Files, want)
+	}
+}
+
+func TestMultipartFormReaderError(t *testing.T) {
+	client := formServer(t, nil)
+	_, err := client.Pets.UploadPhotos(context.Background(), "1", &UploadPhotosForm{
+		Photo: &FormFile{Reader: iotest.ErrReader(errors.New("disk failure")), Filename: "photo.png"},
+	})
+	if err == nil || !strings.Contains(err.Error(), "disk failure") {
+		t.Fatalf("got error %v, want the reader error", err)
+	}
+}
+
+func TestMultipartFormRequiredFile(t *testing.T) {
+	client := formServer(t, nil)
+	_, err := client.Pets.UploadPhotos(context.Background(), "1", &UploadPhotosForm{})
+	if err == nil || err.Error() != "missing required form field photo" {
+		t.Fatalf("got error %v, want the missing field error", err)
+	}
+}
+
+func TestURLEncodedForm(t *testing.T) {
+	client := formServer(t, nil)
+	name := "rex & co"
+	form, err := client.Pets.RenamePet(context.Background(), "1", &RenamePetForm{Name: &name, Tags: []string{"good", "dog"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if form.ContentType != "application/x-www-form-urlencoded" {
+		t.Errorf("got content type %q", form.ContentType)
+	}
+	if strings.Join(form.Values["name"], ",") != name || strings.Join(form.Values["tags"], ",") != "good,dog" {
+		t.Errorf("got values %v", form.Values)
+	}
+}
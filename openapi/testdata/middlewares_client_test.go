@@ -0,0 +1,122 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func petServer(t *testing.T, requests *int32) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(requests, 1)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"name": "` + r.Header.Get("X-Signature") + `"}`))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// recorder returns a middleware recording the requests and responses it
+// sees in calls.
+func recorder(name string, calls *[]string) Middleware {
+	return func(next HTTPClient) HTTPClient {
+		return HTTPClientFunc(func(req *http.Request) (*http.Response, error) {
+			*calls = append(*calls, name+" request")
+			resp, err := next.Do(req)
+			*calls = append(*calls, name+" response")
+			return resp, err
+		})
+	}
+}
+
+func TestMiddlewareOrder(t *testing.T) {
+	var requests int32
+	server := petServer(t, &requests)
+	calls := []string{}
+	client := NewAPIClient(ClientConfiguration{
+		BaseURL: server.URL,
+		HTTPClient: HTTPClientFunc(func(req *http.Request) (*http.Response, error) {
+			calls = append(calls, "http client")
+			return http.DefaultClient.Do(req)
+		}),
+		Middlewares: []Middleware{
+			recorder("first", &calls),
+			RequestEditor(func(req *http.Request) error {
+				req.Header.Set("X-Signature", "signed")
+				return nil
+			}),
+			recorder("second", &calls),
+		},
+	})
+	pet, err := client.Pets.GetPet(context.Background(), "1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pet.Name != "signed" {
+		t.Errorf("got pet %+v, want the signed request", pet)
+	}
+	want := []string{"first request", "second request", "http client", "second response", "first response"}
+	if !reflect.DeepEqual(calls, want) {
+		t.Errorf("got calls %v, want %v", calls, want)
+	}
+}
+
+func TestDefaultHTTPClient(t *testing.T) {
+	var requests int32
+	server := petServer(t, &requests)
+	client := NewAPIClient(ClientConfiguration{BaseURL: server.URL})
+	if _, err := client.Pets.Health(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if requests := atomic.LoadInt32(&requests); requests != 1 {
+		t.Errorf("got %d requests, want 1", requests)
+	}
+}
+
+func TestRequestEditorError(t *testing.T) {
+	var requests int32
+	server := petServer(t, &requests)
+	editErr := errors.New("no credentials")
+	client := NewAPIClient(ClientConfiguration{
+		BaseURL:     server.URL,
+		Middlewares: []Middleware{RequestEditor(func(req *http.Request) error { return editErr })},
+	})
+	if _, err := client.Pets.GetPet(context.Background(), "1"); !errors.Is(err, editErr) {
+		t.Errorf("got error %v, want the editor error", err)
+	}
+	if requests := atomic.LoadInt32(&requests); requests != 0 {
+		t.Errorf("got %d requests, want the request to be aborted", requests)
+	}
+}
+
+func TestResponseInspector(t *testing.T) {
+	var requests int32
+	server := petServer(t, &requests)
+	statusCodes := []int{}
+	client := NewAPIClient(ClientConfiguration{
+		BaseURL: server.URL,
+		Middlewares: []Middleware{
+			ResponseInspector(func(resp *http.Response) error {
+				statusCodes = append(statusCodes, resp.StatusCode)
+				if resp.Request.URL.Path == "/health" {
+					return errors.New("unexpected health check")
+				}
+				return nil
+			}),
+		},
+	})
+	if _, err := client.Pets.GetPet(context.Background(), "1"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.Pets.Health(context.Background()); err == nil || !strings.Contains(err.Error(), "unexpected health check") {
+		t.Errorf("got error %v, want the inspector error", err)
+	}
+	if !reflect.DeepEqual(statusCodes, []int{http.StatusOK, http.StatusOK}) {
+		t.Errorf("got status codes %v", statusCodes)
+	}
+}
@@ -0,0 +1,255 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strconv"
+	"sync"
+	"testing"
+)
+
+const petCount = 7
+
+// paginationServer serves petCount pets, pages have 3 pets unless a limit
+// is requested, requests for the offset failAt fail.
+type paginationServer struct {
+	mu       sync.Mutex
+	requests []string
+	bodies   []string
+	failAt   int
+}
+
+func newPaginationServer(t *testing.T) (*paginationServer, *APIClient) {
+	s := &paginationServer{failAt: -1}
+	server := httptest.NewServer(s)
+	t.Cleanup(server.Close)
+	return s, NewAPIClient(ClientConfiguration{BaseURL: server.URL})
+}
+
+func (s *paginationServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	s.mu.Lock()
+	s.requests = append(s.requests, r.URL.RequestURI())
+	s.bodies = append(s.bodies, string(body))
+	failAt := s.failAt
+	s.mu.Unlock()
+
+	query := r.URL.Query()
+	limit := 3
+	if value := query.Get("limit") + query.Get("per_page"); value != "" {
+		limit, _ = strconv.Atoi(value)
+	}
+	offset, _ := strconv.Atoi(query.Get("offset") + query.Get("cursor") + query.Get("token"))
+	if page, _ := strconv.Atoi(query.Get("page")); page > 1 {
+		offset = (page - 1) * limit
+	}
+	if offset == failAt {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	pets := []Pet{}
+	for i := offset; i < offset+limit && i < petCount; i++ {
+		pets = append(pets, Pet{Name: fmt.Sprintf("pet%d", i)})
+	}
+	next := ""
+	if offset+limit < petCount {
+		next = strconv.Itoa(offset + limit)
+	}
+	if query.Get("cursor") == "stuck" {
+		pets, next = []Pet{{Name: "stuck"}}, "stuck"
+	}
+	var response interface{} = pets
+	switch r.URL.Path {
+	case "/pages":
+		response = map[string]interface{}{"data": pets}
+	case "/cursor":
+		response = map[string]interface{}{"items": pets, "next_cursor": next}
+	case "/search":
+		response = map[string]interface{}{"hits": pets, "continuation": next}
+	case "/link":
+		if next != "" {
+			w.Header().Add("Link", `<https://example.com/docs>; rel="help"`)
+			w.Header().Add("Link", fmt.Sprintf(`</link?limit=%d&offset=%s>; rel="next"`, limit, next))
+		}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+func (s *paginationServer) requested() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string{}, s.requests...)
+}
+
+// itemIteratorOf is implemented by the generated item iterators.
+type itemIteratorOf interface {
+	Next() bool
+	Item() Pet
+	Err() error
+}
+
+func names(t *testing.T, items itemIteratorOf) []string {
+	t.Helper()
+	names := []string{}
+	for items.Next() {
+		names = append(names, items.Item().Name)
+	}
+	if err := items.Err(); err != nil {
+		t.Fatal(err)
+	}
+	return names
+}
+
+func allPets() []string {
+	names := []string{}
+	for i := 0; i < petCount; i++ {
+		names = append(names, fmt.Sprintf("pet%d", i))
+	}
+	return names
+}
+
+func intPtr(i int) *int {
+	return &i
+}
+
+func stringPtr(s string) *string {
+	return &s
+}
+
+func TestPagination(t *testing.T) {
+	tests := []struct {
+		name     string
+		items    func(ctx context.Context, client *APIClient) itemIteratorOf
+		requests []string
+	}{
+		{"offset", func(ctx context.Context, client *APIClient) itemIteratorOf {
+			return client.Pets.ListOffsetAll(ctx, &ListOffsetParams{Limit: intPtr(3)})
+		}, []string{"/offset?limit=3", "/offset?limit=3&offset=3", "/offset?limit=3&offset=6"}},
+		// pages shorter than the limit are the last page, without a limit
+		// the pages are fetched until one is empty.
+		{"offset without limit", func(ctx context.Context, client *APIClient) itemIteratorOf {
+			return client.Pets.ListOffsetAll(ctx, nil)
+		}, []string{"/offset", "/offset?offset=3", "/offset?offset=6", "/offset?offset=7"}},
+		{"page", func(ctx context.Context, client *APIClient) itemIteratorOf {
+			return client.Pets.ListPagesAll(ctx, &ListPagesParams{PerPage: intPtr(3)})
+		}, []string{"/pages?per_page=3", "/pages?page=2&per_page=3", "/pages?page=3&per_page=3"}},
+		{"cursor", func(ctx context.Context, client *APIClient) itemIteratorOf {
+			return client.Pets.ListCursorAll(ctx, &ListCursorParams{Limit: intPtr(4)})
+		}, []string{"/cursor?limit=4", "/cursor?cursor=4&limit=4"}},
+		{"link", func(ctx context.Context, client *APIClient) itemIteratorOf {
+			return client.Pets.ListLinkAll(ctx, &ListLinkParams{Limit: intPtr(3)})
+		}, []string{"/link?limit=3", "/link?limit=3&offset=3", "/link?limit=3&offset=6"}},
+		{"x-pagination", func(ctx context.Context, client *APIClient) itemIteratorOf {
+			return client.Pets.SearchAll(ctx, nil, Pet{Name: "pet"})
+		}, []string{"/search", "/search?token=3", "/search?token=6"}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			server, client := newPaginationServer(t)
+			items := test.items(context.Background(), client)
+			if len(server.requested()) != 0 {
+				t.Error("the first page was fetched before Next")
+			}
+			if got := names(t, items); !reflect.DeepEqual(got, allPets()) {
+				t.Errorf("got pets %v, want %v", got, allPets())
+			}
+			if got := server.requested(); !reflect.DeepEqual(got, test.requests) {
+				t.Errorf("got requests %v, want %v", got, test.requests)
+			}
+		})
+	}
+}
+
+func TestPaginationRequestBody(t *testing.T) {
+	server, client := newPaginationServer(t)
+	names(t, client.Pets.SearchAll(context.Background(), nil, Pet{Name: "pet"}))
+	for _, body := range server.bodies {
+		if body != server.bodies[0] || body == "" {
+			t.Errorf("got bodies %q, want the same body for every page", server.bodies)
+		}
+	}
+}
+
+func TestPages(t *testing.T) {
+	server, client := newPaginationServer(t)
+	pages := client.Pets.ListOffsetPages(context.Background(), &ListOffsetParams{Limit: intPtr(5), Offset: intPtr(1)})
+	sizes := []int{}
+	for pages.Next() {
+		sizes = append(sizes, len(*pages.Page()))
+	}
+	if err := pages.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(sizes, []int{5, 1}) {
+		t.Errorf("got page sizes %v, want [5 1]", sizes)
+	}
+	if pages.Next() {
+		t.Error("Next fetched a page after the last page")
+	}
+	if got := len(server.requested()); got != 2 {
+		t.Errorf("got %d requests, want 2", got)
+	}
+}
+
+func TestPaginationStopsOnRepeatedCursor(t *testing.T) {
+	server, client := newPaginationServer(t)
+	pages := client.Pets.ListCursorPages(context.Background(), &ListCursorParams{Cursor: stringPtr("stuck")})
+	count := 0
+	for pages.Next() {
+		count++
+	}
+	if count != 1 || len(server.requested()) != 1 {
+		t.Errorf("got %d pages and %d requests, want 1", count, len(server.requested()))
+	}
+}
+
+func TestPaginationError(t *testing.T) {
+	server, client := newPaginationServer(t)
+	server.failAt = 3
+	items := client.Pets.ListOffsetAll(context.Background(), &ListOffsetParams{Limit: intPtr(3)})
+	got := []string{}
+	for items.Next() {
+		got = append(got, items.Item().Name)
+	}
+	if !reflect.DeepEqual(got, allPets()[:3]) {
+		t.Errorf("got pets %v, want the first page", got)
+	}
+	var apiErr *APIError
+	if !errors.As(items.Err(), &apiErr) || apiErr.StatusCode != http.StatusInternalServerError {
+		t.Errorf("got error %v, want a 500 api error", items.Err())
+	}
+	if items.Next() {
+		t.Error("Next continued after an error")
+	}
+	if got := len(server.requested()); got != 2 {
+		t.Errorf("got %d requests, want 2", got)
+	}
+}
+
+func TestPaginationContextCanceled(t *testing.T) {
+	server, client := newPaginationServer(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	items := client.Pets.ListLinkAll(ctx, &ListLinkParams{Limit: intPtr(3)})
+	count := 0
+	for items.Next() {
+		count++
+		cancel()
+	}
+	if count != 3 {
+		t.Errorf("got %d pets, want the first page", count)
+	}
+	if !errors.Is(items.Err(), context.Canceled) {
+		t.Errorf("got error %v, want %v", items.Err(), context.Canceled)
+	}
+	if got := len(server.requested()); got != 1 {
+		t.Errorf("got %d requests, want 1", got)
+	}
+}
@@ -0,0 +1,43 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// pathServer responds with the escaped path of the requests.
+func pathServer(t *testing.T) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]string{"url": r.Method + " " + r.URL.EscapedPath()})
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestPathParameters(t *testing.T) {
+	client := NewAPIClient(ClientConfiguration{BaseURL: pathServer(t).URL})
+	photo, err := client.Pets.GetPhoto(context.Background(), 42, "a b/c")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the parameters are in the order of the path placeholders and are
+	// escaped.
+	if want := "GET /pets/42/photos/a%20b%2Fc"; photo.Url != want {
+		t.Errorf("got %q, want %q", photo.Url, want)
+	}
+}
+
+func TestReservedPathParameter(t *testing.T) {
+	client := NewAPIClient(ClientConfiguration{BaseURL: pathServer(t).URL})
+	pets, err := client.Pets.DeletePets(context.Background(), "cat")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "DELETE /pets/cat"; pets.Url != want {
+		t.Errorf("got %q, want %q", pets.Url, want)
+	}
+}
@@ -0,0 +1,212 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// retryServer responds with the status codes in order, the last one is
+// repeated, and counts the requests.
+func retryServer(t *testing.T, header http.Header, statusCodes ...int) (*httptest.Server, *int32) {
+	t.Helper()
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := int(atomic.AddInt32(&requests, 1))
+		if n > len(statusCodes) {
+			n = len(statusCodes)
+		}
+		io.Copy(io.Discard, r.Body)
+		statusCode := statusCodes[n-1]
+		if statusCode >= 400 {
+			for name, values := range header {
+				w.Header()[name] = values
+			}
+			w.WriteHeader(statusCode)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(statusCode)
+		io.WriteString(w, `[{"id":"1"}]`)
+	}))
+	t.Cleanup(server.Close)
+	return server, &requests
+}
+
+func newRetryClient(server *httptest.Server, policy *RetryPolicy) *APIClient {
+	return NewAPIClient(ClientConfiguration{BaseURL: server.URL, Retry: policy})
+}
+
+func statusCode(err error) int {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.StatusCode
+	}
+	return 0
+}
+
+func TestRetryStatusCodes(t *testing.T) {
+	for _, code := range []int{http.StatusServiceUnavailable, http.StatusTooManyRequests} {
+		server, requests := retryServer(t, nil, code, code, http.StatusOK)
+		client := newRetryClient(server, &RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond})
+		items, err := client.Items.ListItems(context.Background())
+		if err != nil {
+			t.Fatalf("%d: %v", code, err)
+		}
+		if len(*items) != 1 || (*items)[0].Id != "1" {
+			t.Errorf("%d: unexpected items %+v", code, *items)
+		}
+		if *requests != 3 {
+			t.Errorf("%d: got %d requests, want 3", code, *requests)
+		}
+	}
+}
+
+func TestRetryNotRetryableStatusCode(t *testing.T) {
+	server, requests := retryServer(t, nil, http.StatusInternalServerError, http.StatusOK)
+	client := newRetryClient(server, &RetryPolicy{InitialBackoff: time.Millisecond})
+	_, err := client.Items.ListItems(context.Background())
+	if statusCode(err) != http.StatusInternalServerError {
+		t.Fatalf("got error %v, want a 500 api error", err)
+	}
+	if *requests != 1 {
+		t.Errorf("got %d requests, want 1", *requests)
+	}
+}
+
+func TestRetryMaxAttempts(t *testing.T) {
+	server, requests := retryServer(t, nil, http.StatusServiceUnavailable)
+	client := newRetryClient(server, &RetryPolicy{MaxAttempts: 4, InitialBackoff: time.Millisecond})
+	_, err := client.Items.ListItems(context.Background())
+	if statusCode(err) != http.StatusServiceUnavailable {
+		t.Fatalf("got error %v, want a 503 api error", err)
+	}
+	if *requests != 4 {
+		t.Errorf("got %d requests, want 4", *requests)
+	}
+}
+
+func TestRetryDisabled(t *testing.T) {
+	server, requests := retryServer(t, nil, http.StatusServiceUnavailable, http.StatusOK)
+	client := newRetryClient(server, nil)
+	_, err := client.Items.ListItems(context.Background())
+	if statusCode(err) != http.StatusServiceUnavailable {
+		t.Fatalf("got error %v, want a 503 api error", err)
+	}
+	if *requests != 1 {
+		t.Errorf("got %d requests, want 1", *requests)
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	tests := []struct {
+		name string
+		// retryAfter returns the Retry-After header.
+		retryAfter func() string
+		maxBackoff time.Duration
+		min, max   time.Duration
+	}{
+		{"seconds", func() string { return "1" }, 0, 900 * time.Millisecond, 5 * time.Second},
+		// http dates are truncated to the second, the wait is at least
+		// 2 seconds.
+		{"http date", func() string { return time.Now().Add(3 * time.Second).UTC().Format(http.TimeFormat) }, 0, time.Second, 5 * time.Second},
+		// the wait is capped by MaxBackoff instead of the deadline.
+		{"capped at max backoff", func() string { return "86400" }, 50 * time.Millisecond, 0, 5 * time.Second},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			header := http.Header{"Retry-After": {test.retryAfter()}}
+			server, requests := retryServer(t, header, http.StatusTooManyRequests, http.StatusOK)
+			// the exponential backoff would be longer than the expected
+			// waits if Retry-After was ignored.
+			client := newRetryClient(server, &RetryPolicy{InitialBackoff: time.Minute, MaxBackoff: test.maxBackoff})
+			ctx, cancel := context.WithTimeout(context.Background(), test.max)
+			defer cancel()
+			start := time.Now()
+			if _, err := client.Items.ListItems(ctx); err != nil {
+				t.Fatal(err)
+			}
+			elapsed := time.Since(start)
+			if elapsed < test.min || elapsed > test.max {
+				t.Errorf("waited %s, want between %s and %s", elapsed, test.min, test.max)
+			}
+			if *requests != 2 {
+				t.Errorf("got %d requests, want 2", *requests)
+			}
+		})
+	}
+}
+
+func TestRetryContextCanceled(t *testing.T) {
+	header := http.Header{"Retry-After": {"5"}}
+	server, requests := retryServer(t, header, http.StatusServiceUnavailable, http.StatusOK)
+	client := newRetryClient(server, &RetryPolicy{})
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err := client.Items.ListItems(ctx)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("got error %v, want %v", err, context.DeadlineExceeded)
+	}
+	if *requests != 1 {
+		t.Errorf("got %d requests, want 1", *requests)
+	}
+}
+
+func TestRetryPost(t *testing.T) {
+	server, requests := retryServer(t, nil, http.StatusServiceUnavailable, http.StatusOK)
+	client := newRetryClient(server, &RetryPolicy{InitialBackoff: time.Millisecond})
+	_, err := client.Items.CreateItem(context.Background(), Item{Name: "item"})
+	if statusCode(err) != http.StatusServiceUnavailable {
+		t.Fatalf("got error %v, want a 503 api error", err)
+	}
+	if *requests != 1 {
+		t.Errorf("got %d requests, want 1", *requests)
+	}
+}
+
+func TestRetryRetryablePost(t *testing.T) {
+	var bodies []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, string(body))
+		if len(bodies) == 1 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, `[]`)
+	}))
+	defer server.Close()
+	client := newRetryClient(server, &RetryPolicy{InitialBackoff: time.Millisecond})
+	if _, err := client.Items.SearchItems(context.Background(), Item{Name: "item"}); err != nil {
+		t.Fatal(err)
+	}
+	if len(bodies) != 2 {
+		t.Fatalf("got %d requests, want 2", len(bodies))
+	}
+	if bodies[0] == "" || bodies[0] != bodies[1] {
+		t.Errorf("the retried body %q is not the sent body %q", bodies[1], bodies[0])
+	}
+}
+
+// TestRetryStreamedBody checks that retryable operations are not retried
+// when their body is streamed and can not be replayed.
+func TestRetryStreamedBody(t *testing.T) {
+	server, requests := retryServer(t, nil, http.StatusServiceUnavailable, http.StatusNoContent)
+	client := newRetryClient(server, &RetryPolicy{InitialBackoff: time.Millisecond})
+	_, err := client.Items.UploadItemData(context.Background(), "1", &UploadItemDataForm{
+		Data: &FormFile{Reader: strings.NewReader("data"), Filename: "data.bin"},
+	})
+	if statusCode(err) != http.StatusServiceUnavailable {
+		t.Fatalf("got error %v, want a 503 api error", err)
+	}
+	if *requests != 1 {
+		t.Errorf("got %d requests, want 1", *requests)
+	}
+}
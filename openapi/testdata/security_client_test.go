@@ -0,0 +1,128 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+)
+
+// securityServer echoes the credentials of the requests and issues
+// client credentials tokens.
+func securityServer(t *testing.T, tokenRequests *int32) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path == "/oauth/token" {
+			atomic.AddInt32(tokenRequests, 1)
+			id, secret, _ := r.BasicAuth()
+			r.ParseForm()
+			if id != "id" || secret != "secret" || r.PostForm.Get("grant_type") != "client_credentials" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"access_token": "token:" + r.PostForm.Get("scope"),
+				"expires_in":   3600,
+			})
+			return
+		}
+		echo := Echo{
+			Authorization: r.Header.Get("Authorization"),
+			Key:           r.Header.Get("X-API-Key"),
+			Query:         r.URL.Query().Get("api_key"),
+		}
+		if cookie, err := r.Cookie("session"); err == nil {
+			echo.Cookie = cookie.Value
+		}
+		json.NewEncoder(w).Encode(echo)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// asEcho converts the response of an operation into an Echo.
+func asEcho(response interface{}, err error) (Echo, error) {
+	echo := Echo{}
+	if err != nil {
+		return echo, err
+	}
+	data, err := json.Marshal(response)
+	if err != nil {
+		return echo, err
+	}
+	err = json.Unmarshal(data, &echo)
+	return echo, err
+}
+
+func TestSecuritySchemes(t *testing.T) {
+	var tokenRequests int32
+	server := securityServer(t, &tokenRequests)
+	client := NewAPIClient(ClientConfiguration{
+		BaseURL:      server.URL,
+		ApiKeyHeader: "header-key",
+		ApiKeyQuery:  "query-key",
+		ApiKeyCookie: "cookie-key",
+		BasicAuth:    &BasicAuth{Username: "user", Password: "pass"},
+		Oauth:        &ClientCredentials{ClientID: "id", ClientSecret: "secret"},
+	})
+	ctx := context.Background()
+	basic := "Basic dXNlcjpwYXNz"
+	tests := []struct {
+		name string
+		call func() (Echo, error)
+		want Echo
+	}{
+		{"global security", func() (Echo, error) { return asEcho(client.Auth.GetDefault(ctx)) }, Echo{Key: "header-key"}},
+		{"no security", func() (Echo, error) { return asEcho(client.Auth.GetPublic(ctx)) }, Echo{}},
+		{"query api key", func() (Echo, error) { return asEcho(client.Auth.GetQuery(ctx)) }, Echo{Query: "query-key"}},
+		{"cookie api key", func() (Echo, error) { return asEcho(client.Auth.GetCookie(ctx)) }, Echo{Cookie: "cookie-key"}},
+		{"basic", func() (Echo, error) { return asEcho(client.Auth.GetBasic(ctx)) }, Echo{Authorization: basic}},
+		// the bearer credential is not configured, the next requirement is used.
+		{"alternative requirement", func() (Echo, error) { return asEcho(client.Auth.GetEither(ctx)) }, Echo{Authorization: basic}},
+		{"oauth2", func() (Echo, error) { return asEcho(client.Auth.GetOauth(ctx)) }, Echo{Authorization: "Bearer token:read write"}},
+	}
+	for _, test := range tests {
+		echo, err := test.call()
+		if err != nil {
+			t.Fatalf("%s: %v", test.name, err)
+		}
+		if echo != test.want {
+			t.Errorf("%s: got credentials %+v, want %+v", test.name, echo, test.want)
+		}
+	}
+
+	// the access token is cached.
+	if _, err := client.Auth.GetOauth(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if tokenRequests != 1 {
+		t.Errorf("got %d token requests, want 1", tokenRequests)
+	}
+}
+
+func TestBearerToken(t *testing.T) {
+	client := NewAPIClient(ClientConfiguration{
+		BaseURL:    securityServer(t, new(int32)).URL,
+		BearerAuth: StaticToken("static"),
+		BasicAuth:  &BasicAuth{Username: "user", Password: "pass"},
+	})
+	echo, err := client.Auth.GetEither(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if echo.Authorization != "Bearer static" {
+		t.Errorf("got Authorization %q, want %q", echo.Authorization, "Bearer static")
+	}
+}
+
+func TestClientCredentialsError(t *testing.T) {
+	client := NewAPIClient(ClientConfiguration{
+		BaseURL: securityServer(t, new(int32)).URL,
+		Oauth:   &ClientCredentials{ClientID: "id", ClientSecret: "wrong"},
+	})
+	if _, err := client.Auth.GetOauth(context.Background()); err == nil {
+		t.Error("expected a token error")
+	}
+}
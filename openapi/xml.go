@@ -0,0 +1,46 @@
+package openapi
+
+import "fmt"
+
+// XML contains the xml serialization options of a schema.
+type XML struct {
+	Name      string `json:"name" yaml:"name"`
+	Wrapped   bool   `json:"wrapped" yaml:"wrapped"`
+	Attribute bool   `json:"attribute" yaml:"attribute"`
+}
+
+// xmlName returns the xml element name of a schema, the names declared on
+// referenced schemas are used for references without a name.
+func xmlName(schema *OpenAPISchema, property Property) string {
+	if property.XML.Name != "" || property.Ref == "" {
+		return property.XML.Name
+	}
+	if definition, ok := schema.RefPropertyMap[property.Ref]; ok {
+		return definition.XML.Name
+	}
+	return ""
+}
+
+// xmlTag returns the xml struct tag of an object property, the elements of
+// wrapped arrays are nested in the element of the property.
+func xmlTag(schema *OpenAPISchema, name string, property Property) string {
+	if xmlName := property.XML.Name; xmlName != "" {
+		name = xmlName
+	}
+	if property.XML.Attribute {
+		return fmt.Sprintf(`xml:"%s,attr,omitempty"`, name)
+	}
+	if property.Type == "array" && property.Items != nil {
+		itemName := xmlName(schema, *property.Items)
+		if property.XML.Wrapped {
+			if itemName == "" {
+				itemName = name
+			}
+			return fmt.Sprintf(`xml:"%s>%s,omitempty"`, name, itemName)
+		}
+		if itemName != "" {
+			name = itemName
+		}
+	}
+	return fmt.Sprintf(`xml:"%s,omitempty"`, name)
+}
@@ -0,0 +1,31 @@
+package openapi
+
+import "testing"
+
+func TestXMLTag(t *testing.T) {
+	schema := &OpenAPISchema{RefPropertyMap: map[string]Property{
+		"#/components/schemas/Tag": {Type: "object", XML: XML{Name: "tag"}},
+	}}
+	tests := []struct {
+		name     string
+		property Property
+		want     string
+	}{
+		{"element", Property{Type: "string"}, `xml:"name,omitempty"`},
+		{"renamed", Property{Type: "string", XML: XML{Name: "Name"}}, `xml:"Name,omitempty"`},
+		{"attribute", Property{Type: "integer", XML: XML{Attribute: true}}, `xml:"name,attr,omitempty"`},
+		{"array", Property{Type: "array", Items: &Property{Type: "string"}}, `xml:"name,omitempty"`},
+		{"array of named items", Property{Type: "array", Items: &Property{Type: "string", XML: XML{Name: "item"}}}, `xml:"item,omitempty"`},
+		{"wrapped array", Property{Type: "array", XML: XML{Wrapped: true}, Items: &Property{Type: "string"}}, `xml:"name>name,omitempty"`},
+		{"wrapped array of references", Property{Type: "array", XML: XML{Wrapped: true}, Items: &Property{Ref: "#/components/schemas/Tag"}}, `xml:"name>tag,omitempty"`},
+	}
+	for _, test := range tests {
+		if got := xmlTag(schema, "name", test.property); got != test.want {
+			t.Errorf("%s: got %s, want %s", test.name, got, test.want)
+		}
+	}
+}
+
+func TestGeneratedCodecs(t *testing.T) {
+	testClient(t, "testdata/codecs.yaml", "testdata/codecs_client_test.go")
+}